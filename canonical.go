@@ -0,0 +1,147 @@
+package jp
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Canonicalize returns the canonical form of json as defined by the RFC 8785
+// JSON Canonicalization Scheme (JCS). The canonical form contains no
+// whitespace, object members are sorted by the UTF-16 code units of their
+// keys, numbers are serialized as they would be by ECMAScript, and strings
+// use the minimal set of escape sequences.
+//
+// RFC 8785 requires I-JSON input, so json is validated as it would be by
+// ValidateWithOptions with Strict set. An error is returned if json is not
+// valid, if a string contains invalid UTF-8 or a lone UTF-16 surrogate, if an
+// object contains duplicate keys, or if a number cannot be represented as a
+// float64. If json is not valid, the error is a *SyntaxError.
+func Canonicalize[T Stringlike](json T) ([]byte, error) {
+	if err := validate(json, ValidateOptions{Strict: true}); err != nil {
+		return nil, err
+	}
+	return appendCanonical(nil, Parse(json))
+}
+
+// canonicalMember is an object member whose key has been unescaped.
+type canonicalMember[T Stringlike] struct {
+	key   string
+	value Result[T]
+}
+
+func appendCanonical[T Stringlike](buf []byte, value Result[T]) ([]byte, error) {
+	var err error
+	switch value.Type {
+	case Null:
+		return append(buf, "null"...), nil
+	case False:
+		return append(buf, "false"...), nil
+	case True:
+		return append(buf, "true"...), nil
+	case String:
+		return appendCanonicalString(buf, value.Str)
+	case Number:
+		return appendCanonicalNumber(buf, value.Num)
+	}
+
+	if value.IsArray() {
+		buf = append(buf, '[')
+		for i, element := range value.Array() {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			if buf, err = appendCanonical(buf, element); err != nil {
+				return nil, err
+			}
+		}
+		return append(buf, ']'), nil
+	}
+
+	var members []canonicalMember[T]
+	value.ForEach(func(key, value Result[T]) bool {
+		members = append(members, canonicalMember[T]{key: key.Str, value: value})
+		return true
+	})
+	sort.SliceStable(members, func(i, j int) bool {
+		return lessUTF16(members[i].key, members[j].key)
+	})
+
+	buf = append(buf, '{')
+	for i, m := range members {
+		if i > 0 {
+			if m.key == members[i-1].key {
+				return nil, errors.New("duplicate key " + strconv.Quote(m.key))
+			}
+			buf = append(buf, ',')
+		}
+		if buf, err = appendCanonicalString(buf, m.key); err != nil {
+			return nil, err
+		}
+		buf = append(buf, ':')
+		if buf, err = appendCanonical(buf, m.value); err != nil {
+			return nil, err
+		}
+	}
+	return append(buf, '}'), nil
+}
+
+// appendCanonicalString appends s to buf as a JSON string. Unlike
+// appendQuoted, it returns an error rather than replacing invalid UTF-8 so
+// that distinct inputs never share a canonical form.
+func appendCanonicalString(buf []byte, s string) ([]byte, error) {
+	if !utf8.ValidString(s) {
+		return nil, errors.New("string contains invalid UTF-8")
+	}
+	return appendQuoted(buf, s), nil
+}
+
+// appendCanonicalNumber appends f to buf as it would be serialized by the
+// ECMAScript Number.prototype.toString method.
+func appendCanonicalNumber(buf []byte, f float64) ([]byte, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, errors.New("number out of range")
+	}
+	if f == 0 {
+		return append(buf, '0'), nil
+	}
+
+	abs := math.Abs(f)
+	if abs >= 1e-6 && abs < 1e21 {
+		return strconv.AppendFloat(buf, f, 'f', -1, 64), nil
+	}
+
+	// ECMAScript does not pad the exponent to two digits
+	buf = strconv.AppendFloat(buf, f, 'e', -1, 64)
+	n := len(buf)
+	if buf[n-2] == '0' && (buf[n-3] == '-' || buf[n-3] == '+') {
+		buf[n-2] = buf[n-1]
+		buf = buf[:n-1]
+	}
+	return buf, nil
+}
+
+// lessUTF16 returns true if a sorts before b when both are compared as
+// sequences of UTF-16 code units.
+func lessUTF16(a, b string) bool {
+	for a != "" && b != "" {
+		ra, na := utf8.DecodeRuneInString(a)
+		rb, nb := utf8.DecodeRuneInString(b)
+		if ra != rb {
+			return firstUTF16(ra) < firstUTF16(rb) ||
+				firstUTF16(ra) == firstUTF16(rb) && ra < rb
+		}
+		a, b = a[na:], b[nb:]
+	}
+	return a == "" && b != ""
+}
+
+// firstUTF16 returns the first UTF-16 code unit of r's encoding.
+func firstUTF16(r rune) rune {
+	if r < 0x10000 {
+		return r
+	}
+	return 0xd800 + (r-0x10000)>>10
+}
@@ -0,0 +1,107 @@
+package jp
+
+import (
+	"testing"
+)
+
+func TestCanonicalize(t *testing.T) {
+	cases := []struct {
+		json     string
+		expected string
+	}{
+		// RFC 8785, section 3.2.2
+		{
+			`{
+			  "numbers": [333333333.33333329, 1E30, 4.50,
+			              2e-3, 0.000000000000000000000000001],
+			  "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+			  "literals": [null, true, false]
+			}`,
+			`{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		// RFC 8785, section 3.2.3
+		{
+			`{
+			  "\u20ac": "Euro Sign",
+			  "\r": "Carriage Return",
+			  "\ufb33": "Hebrew Letter Dalet With Dagesh",
+			  "1": "One",
+			  "\ud83d\ude00": "Emoji: Grinning Face",
+			  "\u0080": "Control",
+			  "\u00f6": "Latin Small Letter O With Diaeresis"
+			}`,
+			"{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"\u00f6\":\"Latin Small Letter O With Diaeresis\"," +
+				"\"\u20ac\":\"Euro Sign\",\"\U0001f600\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}",
+		},
+		{`{"b":[],"a":{"d":{},"c":1}}`, `{"a":{"c":1,"d":{}},"b":[]}`},
+		{` "x" `, `"x"`},
+		{`[-0, 0.0, 1e0]`, `[0,0,1]`},
+	}
+	for _, c := range cases {
+		t.Run(c.expected, func(t *testing.T) {
+			actual, err := Canonicalize(c.json)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(actual) != c.expected {
+				t.Fatalf("expected %s, got %s", c.expected, actual)
+			}
+
+			actual, err = Canonicalize([]byte(c.json))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(actual) != c.expected {
+				t.Fatalf("expected %s, got %s", c.expected, actual)
+			}
+		})
+	}
+
+	for _, json := range []string{`{"a":1,"a":2}`, `[1e400]`, `{`, `[1,]`} {
+		_, err := Canonicalize(json)
+		assert(t, err != nil)
+	}
+
+	// inputs that are not I-JSON are rejected rather than sharing the
+	// canonical form of ["\ufffd"]
+	for _, json := range []string{`["\ud800"]`, `["\udc00"]`, "[\"\xff\"]", "{\"\xff\":1}", `{"\ud800":1}`} {
+		_, err := Canonicalize(json)
+		assert(t, err != nil)
+		_, err = Canonicalize([]byte(json))
+		assert(t, err != nil)
+	}
+	actual, err := Canonicalize(`["\ufffd"]`)
+	assert(t, err == nil && string(actual) == "[\"\ufffd\"]")
+
+	// appendCanonicalString does not substitute U+FFFD
+	_, err = appendCanonicalString(nil, "\xff")
+	assert(t, err != nil)
+}
+
+func TestCanonicalNumbers(t *testing.T) {
+	// ECMAScript serializations from RFC 8785, appendix B
+	cases := map[float64]string{
+		0:                       "0",
+		1e-7:                    "1e-7",
+		1e-6:                    "0.000001",
+		1e21:                    "1e+21",
+		1e20:                    "100000000000000000000",
+		-5e-324:                 "-5e-324",
+		1.7976931348623157e308:  "1.7976931348623157e+308",
+		-1.7976931348623157e308: "-1.7976931348623157e+308",
+		9007199254740992:        "9007199254740992",
+		-9007199254740992:       "-9007199254740992",
+		295147905179352830000:   "295147905179352830000",
+		333333333.3333332:       "333333333.3333332",
+		4.5:                     "4.5",
+	}
+	for f, expected := range cases {
+		actual, err := appendCanonicalNumber(nil, f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(actual) != expected {
+			t.Fatalf("expected %v, got %s", expected, actual)
+		}
+	}
+}
@@ -0,0 +1,590 @@
+package jp
+
+import (
+	"encoding"
+	"encoding/base64"
+	encjson "encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DecodeError describes a JSON value that could not be decoded into a Go
+// value.
+type DecodeError struct {
+	// Pointer is the JSON pointer of the value, relative to the value on
+	// which Decode was called.
+	Pointer string
+	// Type is the Go type that the value could not be decoded into.
+	Type reflect.Type
+	// Err is the underlying error. Errors returned by the UnmarshalJSON and
+	// UnmarshalText methods of the Go value are returned as-is.
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("decoding JSON value at %q into %v: %v", e.Pointer, e.Type, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Decode stores the value in the Go value pointed to by v, which must be a
+// non-nil pointer. Values are decoded as they would be by encoding/json's
+// Unmarshal:
+//
+//   - objects are decoded into structs, whose fields are matched by their
+//     json tags or names, and into maps with string, integer or
+//     encoding.TextUnmarshaler keys. Fields of embedded structs are promoted.
+//   - arrays are decoded into slices and arrays
+//   - strings are decoded into strings, into []byte from base64, and into
+//     encoding.TextUnmarshaler implementations
+//   - numbers are decoded into integers, floats and json.Number. As with
+//     encoding/json, a string that holds a valid number may also be decoded
+//     into json.Number.
+//   - null sets pointers, interfaces, maps and slices to nil, and leaves
+//     other values unchanged
+//   - values are decoded into empty interfaces as they are by Value
+//
+// Values whose types implement json.Unmarshaler are passed their raw JSON.
+// Pointers are allocated as necessary. Object members that do not match a
+// struct field are ignored. If an object has duplicate keys, the first member
+// with a given key is used, as it is by Get.
+//
+// Decoding stops at the first value that cannot be decoded, and the returned
+// error is a *DecodeError that identifies the value by its JSON pointer.
+func (t Result[T]) Decode(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("cannot decode into non-pointer %T", v)
+	}
+	if !t.Exists() {
+		return &DecodeError{Type: rv.Type().Elem(), Err: errors.New("value does not exist")}
+	}
+	var d decoder[T]
+	return d.value(t, rv)
+}
+
+var (
+	jsonNumberType      = reflect.TypeOf(encjson.Number(""))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// decoder decodes JSON values into Go values.
+type decoder[T Stringlike] struct {
+	// path holds the reference tokens of the value being decoded.
+	path []string
+}
+
+// fail returns a DecodeError for the value being decoded.
+func (d *decoder[T]) fail(typ reflect.Type, err error) error {
+	var b strings.Builder
+	for _, token := range d.path {
+		b.WriteByte('/')
+		writeEscapedToken(&b, token)
+	}
+	return &DecodeError{Pointer: b.String(), Type: typ, Err: err}
+}
+
+// mismatch returns a DecodeError for a value whose JSON type cannot be decoded
+// into typ.
+func (d *decoder[T]) mismatch(r Result[T], typ reflect.Type) error {
+	var kind string
+	switch {
+	case r.IsObject():
+		kind = "object"
+	case r.IsArray():
+		kind = "array"
+	case r.Type == String:
+		kind = "string"
+	case r.Type == Number:
+		kind = "number " + string(r.Raw)
+	case r.IsBool():
+		kind = "bool"
+	default:
+		kind = "null"
+	}
+	return d.fail(typ, fmt.Errorf("cannot decode JSON %s", kind))
+}
+
+// indirect walks down v, allocating pointers as necessary, until it reaches a
+// value that is not a pointer or that implements json.Unmarshaler or
+// encoding.TextUnmarshaler. If null is true, indirect stops at the first
+// settable pointer so that it can be set to nil.
+func indirect(v reflect.Value, null bool) (encjson.Unmarshaler, encoding.TextUnmarshaler, reflect.Value) {
+	// methods with pointer receivers are only available through the pointer
+	if v.Kind() != reflect.Pointer && v.Type().Name() != "" && v.CanAddr() {
+		v = v.Addr()
+	}
+	for {
+		// decode into the value held by an interface if it is a non-nil
+		// pointer
+		if v.Kind() == reflect.Interface && !v.IsNil() {
+			if e := v.Elem(); e.Kind() == reflect.Pointer && !e.IsNil() && (!null || e.Elem().Kind() == reflect.Pointer) {
+				v = e
+				continue
+			}
+		}
+		if v.Kind() != reflect.Pointer || null && v.CanSet() {
+			break
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		if v.Type().NumMethod() > 0 && v.CanInterface() {
+			if u, ok := v.Interface().(encjson.Unmarshaler); ok {
+				return u, nil, reflect.Value{}
+			}
+			if !null {
+				if u, ok := v.Interface().(encoding.TextUnmarshaler); ok {
+					return nil, u, reflect.Value{}
+				}
+			}
+		}
+		v = v.Elem()
+	}
+	return nil, nil, v
+}
+
+// value decodes r into v.
+func (d *decoder[T]) value(r Result[T], v reflect.Value) error {
+	null := r.Type == Null
+	u, tu, v := indirect(v, null)
+	switch {
+	case u != nil:
+		if err := u.UnmarshalJSON([]byte(r.Raw)); err != nil {
+			return d.fail(reflect.TypeOf(u), err)
+		}
+		return nil
+	case tu != nil:
+		if r.Type != String {
+			return d.mismatch(r, reflect.TypeOf(tu))
+		}
+		if err := tu.UnmarshalText([]byte(r.Str)); err != nil {
+			return d.fail(reflect.TypeOf(tu), err)
+		}
+		return nil
+	}
+
+	// values are decoded into empty interfaces as they are by Value
+	if v.Kind() == reflect.Interface && v.NumMethod() == 0 {
+		if null {
+			v.Set(reflect.Zero(v.Type()))
+		} else {
+			v.Set(reflect.ValueOf(r.Value()))
+		}
+		return nil
+	}
+
+	switch {
+	case null:
+		switch v.Kind() {
+		case reflect.Interface, reflect.Pointer, reflect.Map, reflect.Slice:
+			v.Set(reflect.Zero(v.Type()))
+		}
+		return nil
+	case r.IsObject():
+		return d.object(r, v)
+	case r.IsArray():
+		return d.array(r, v)
+	case r.IsBool():
+		if v.Kind() != reflect.Bool {
+			return d.mismatch(r, v.Type())
+		}
+		v.SetBool(r.Type == True)
+		return nil
+	case r.Type == String:
+		return d.string(r, v)
+	case r.Type == Number:
+		return d.number(r, v)
+	default:
+		return d.mismatch(r, v.Type())
+	}
+}
+
+// string decodes the string r into v.
+func (d *decoder[T]) string(r Result[T], v reflect.Value) error {
+	switch {
+	case v.Type() == jsonNumberType:
+		if !validNumber(r.Str) {
+			return d.fail(v.Type(), fmt.Errorf("invalid number literal %q", r.Str))
+		}
+		v.SetString(r.Str)
+	case v.Kind() == reflect.String:
+		v.SetString(r.Str)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		b, err := base64.StdEncoding.DecodeString(r.Str)
+		if err != nil {
+			return d.fail(v.Type(), err)
+		}
+		v.SetBytes(b)
+	default:
+		return d.mismatch(r, v.Type())
+	}
+	return nil
+}
+
+// validNumber returns true if s is a JSON number.
+func validNumber(s string) bool {
+	if s == "" || s[0] != '-' && (s[0] < '0' || s[0] > '9') {
+		return false
+	}
+	v := validator[string]{data: s}
+	end, ok := v.number(1)
+	return ok && end == len(s)
+}
+
+// number decodes the number r into v.
+func (d *decoder[T]) number(r Result[T], v reflect.Value) error {
+	raw := string(r.Raw)
+	overflow := func() error {
+		return d.fail(v.Type(), errors.New("number "+raw+" overflows "+v.Type().String()))
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		switch {
+		case errors.Is(err, strconv.ErrRange):
+			return overflow()
+		case err != nil:
+			return d.mismatch(r, v.Type())
+		case v.OverflowInt(n):
+			return overflow()
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(raw, 10, 64)
+		switch {
+		case errors.Is(err, strconv.ErrRange):
+			return overflow()
+		case err != nil:
+			return d.mismatch(r, v.Type())
+		case v.OverflowUint(n):
+			return overflow()
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f := r.Float()
+		if v.OverflowFloat(f) {
+			return overflow()
+		}
+		v.SetFloat(f)
+	case reflect.String:
+		if v.Type() != jsonNumberType {
+			return d.mismatch(r, v.Type())
+		}
+		v.SetString(raw)
+	default:
+		return d.mismatch(r, v.Type())
+	}
+	return nil
+}
+
+// array decodes the array r into v.
+func (d *decoder[T]) array(r Result[T], v reflect.Value) error {
+	kind := v.Kind()
+	if kind != reflect.Slice && kind != reflect.Array {
+		return d.mismatch(r, v.Type())
+	}
+
+	i := 0
+	it := r.Range()
+	for ; it.Next(); i++ {
+		if kind == reflect.Slice && i >= v.Cap() {
+			v.Set(reflect.Append(v.Slice(0, v.Cap()), reflect.Zero(v.Type().Elem())))
+		}
+		if i >= v.Len() {
+			if kind == reflect.Array {
+				// elements past the end of an array are discarded
+				continue
+			}
+			v.SetLen(i + 1)
+		}
+		elem := v.Index(i)
+		if kind == reflect.Slice {
+			// elements are decoded into fresh values, as they are by
+			// encoding/json
+			elem.Set(reflect.Zero(elem.Type()))
+		}
+
+		d.path = append(d.path, strconv.Itoa(i))
+		err := d.value(it.Value(), elem)
+		d.path = d.path[:len(d.path)-1]
+		if err != nil {
+			return err
+		}
+	}
+	if err := it.Err(); err != nil {
+		return d.fail(v.Type(), err)
+	}
+
+	switch {
+	case kind == reflect.Array:
+		for ; i < v.Len(); i++ {
+			v.Index(i).Set(reflect.Zero(v.Type().Elem()))
+		}
+	case i == 0:
+		v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+	default:
+		v.SetLen(i)
+	}
+	return nil
+}
+
+// object decodes the object r into v.
+func (d *decoder[T]) object(r Result[T], v reflect.Value) error {
+	var fields *decodeFields
+	switch v.Kind() {
+	case reflect.Map:
+		switch kt := v.Type().Key(); {
+		case reflect.PointerTo(kt).Implements(textUnmarshalerType):
+		case kt.Kind() == reflect.String:
+		case kt.Kind() >= reflect.Int && kt.Kind() <= reflect.Uintptr:
+		default:
+			return d.mismatch(r, v.Type())
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+	case reflect.Struct:
+		fields = cachedDecodeFields(v.Type())
+	default:
+		return d.mismatch(r, v.Type())
+	}
+
+	var seen map[string]bool
+	it := r.Range()
+	for it.Next() {
+		key := it.Key().Str
+		if seen[key] {
+			continue
+		}
+		if seen == nil {
+			seen = map[string]bool{}
+		}
+		seen[key] = true
+
+		d.path = append(d.path, key)
+		var err error
+		if fields != nil {
+			err = d.field(fields, key, it.Value(), v)
+		} else {
+			err = d.mapEntry(key, it.Value(), v)
+		}
+		d.path = d.path[:len(d.path)-1]
+		if err != nil {
+			return err
+		}
+	}
+	if err := it.Err(); err != nil {
+		return d.fail(v.Type(), err)
+	}
+	return nil
+}
+
+// mapEntry decodes the member key: value into the map m.
+func (d *decoder[T]) mapEntry(key string, value Result[T], m reflect.Value) error {
+	kt := m.Type().Key()
+
+	var k reflect.Value
+	switch {
+	case reflect.PointerTo(kt).Implements(textUnmarshalerType):
+		k = reflect.New(kt)
+		if err := k.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(key)); err != nil {
+			return d.fail(kt, err)
+		}
+		k = k.Elem()
+	case kt.Kind() == reflect.String:
+		k = reflect.ValueOf(key).Convert(kt)
+	case kt.Kind() >= reflect.Int && kt.Kind() <= reflect.Int64:
+		n, err := strconv.ParseInt(key, 10, 64)
+		if err != nil || reflect.Zero(kt).OverflowInt(n) {
+			return d.fail(kt, fmt.Errorf("invalid map key %q", key))
+		}
+		k = reflect.ValueOf(n).Convert(kt)
+	default:
+		n, err := strconv.ParseUint(key, 10, 64)
+		if err != nil || reflect.Zero(kt).OverflowUint(n) {
+			return d.fail(kt, fmt.Errorf("invalid map key %q", key))
+		}
+		k = reflect.ValueOf(n).Convert(kt)
+	}
+
+	elem := reflect.New(m.Type().Elem()).Elem()
+	if err := d.value(value, elem); err != nil {
+		return err
+	}
+	m.SetMapIndex(k, elem)
+	return nil
+}
+
+// field decodes the member key: value into the matching field of the struct
+// v, if any.
+func (d *decoder[T]) field(fields *decodeFields, key string, value Result[T], v reflect.Value) error {
+	f := fields.lookup(key)
+	if f == nil {
+		return nil
+	}
+	for i, index := range f.index {
+		if i > 0 {
+			// allocate embedded pointers to structs on the way to the field
+			if v.Kind() == reflect.Pointer {
+				if v.IsNil() {
+					if !v.CanSet() {
+						return d.fail(v.Type().Elem(), errors.New("cannot set embedded pointer to unexported struct"))
+					}
+					v.Set(reflect.New(v.Type().Elem()))
+				}
+				v = v.Elem()
+			}
+		}
+		v = v.Field(index)
+	}
+	return d.value(value, v)
+}
+
+// decodeField is a struct field that can be decoded into.
+type decodeField struct {
+	name string
+	// index is the sequence of field indices that lead to the field.
+	index []int
+	// tagged is true if the field's name comes from a json tag.
+	tagged bool
+}
+
+// decodeFields describes the fields of a struct type that can be decoded
+// into.
+type decodeFields struct {
+	list   []decodeField
+	byName map[string]int
+}
+
+// lookup returns the field with the given name. Names that do not match a
+// field exactly are matched case-insensitively.
+func (fs *decodeFields) lookup(name string) *decodeField {
+	if i, ok := fs.byName[name]; ok {
+		return &fs.list[i]
+	}
+	for i := range fs.list {
+		if strings.EqualFold(fs.list[i].name, name) {
+			return &fs.list[i]
+		}
+	}
+	return nil
+}
+
+var decodeFieldCache sync.Map // map[reflect.Type]*decodeFields
+
+// cachedDecodeFields returns the decodable fields of the struct type t.
+func cachedDecodeFields(t reflect.Type) *decodeFields {
+	if fs, ok := decodeFieldCache.Load(t); ok {
+		return fs.(*decodeFields)
+	}
+	fs, _ := decodeFieldCache.LoadOrStore(t, typeDecodeFields(t))
+	return fs.(*decodeFields)
+}
+
+// typeDecodeFields computes the decodable fields of the struct type t. As in
+// encoding/json, the fields of embedded structs are promoted, and a promoted
+// field is hidden by a field with the same name at a shallower depth. Fields
+// with the same name at the same depth hide each other unless exactly one of
+// them is tagged.
+func typeDecodeFields(t reflect.Type) *decodeFields {
+	type candidate struct {
+		typ   reflect.Type
+		index []int
+	}
+
+	var fields []decodeField
+	// taken holds the names of the fields at shallower depths, including
+	// those that were hidden by conflicts
+	taken := map[string]bool{}
+	visited := map[reflect.Type]bool{}
+	next := []candidate{{typ: t}}
+	for len(next) > 0 {
+		current := next
+		next = nil
+
+		// fields found at this depth, by name
+		var level []decodeField
+		counts := map[string]int{}
+		for _, c := range current {
+			if visited[c.typ] {
+				continue
+			}
+			visited[c.typ] = true
+
+			for i := 0; i < c.typ.NumField(); i++ {
+				sf := c.typ.Field(i)
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				name, _, _ := strings.Cut(tag, ",")
+
+				ft := sf.Type
+				if sf.Anonymous {
+					if ft.Kind() == reflect.Pointer {
+						ft = ft.Elem()
+					}
+					if !sf.IsExported() && ft.Kind() != reflect.Struct {
+						continue
+					}
+					if name == "" && ft.Kind() == reflect.Struct {
+						index := append(append([]int(nil), c.index...), i)
+						next = append(next, candidate{typ: ft, index: index})
+						continue
+					}
+				} else if !sf.IsExported() {
+					continue
+				}
+
+				tagged := name != ""
+				if !tagged {
+					name = sf.Name
+				}
+				index := append(append([]int(nil), c.index...), i)
+				level = append(level, decodeField{name: name, index: index, tagged: tagged})
+				counts[name]++
+			}
+		}
+
+		// fields at this depth are hidden by fields at shallower depths, and
+		// conflicting fields at the same depth hide each other
+		for _, f := range level {
+			if taken[f.name] {
+				continue
+			}
+			if counts[f.name] > 1 && (!f.tagged || countTagged(level, f.name) != 1) {
+				continue
+			}
+			fields = append(fields, f)
+		}
+		for _, f := range level {
+			taken[f.name] = true
+		}
+	}
+
+	fs := &decodeFields{list: fields, byName: make(map[string]int, len(fields))}
+	for i, f := range fields {
+		fs.byName[f.name] = i
+	}
+	return fs
+}
+
+// countTagged returns the number of tagged fields in level with the given
+// name.
+func countTagged(level []decodeField, name string) int {
+	n := 0
+	for _, f := range level {
+		if f.name == name && f.tagged {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,243 @@
+package jp
+
+import (
+	encjson "encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type decodeName struct {
+	First string `json:"first"`
+	Last  string `json:"last"`
+}
+
+type decodeBase struct {
+	ID      int    `json:"id"`
+	Created string `json:"created"`
+	Hidden  string
+}
+
+// DecodeMeta is exported so that a pointer to it can be embedded and
+// allocated.
+type DecodeMeta struct {
+	Hidden string
+	Tags   []string `json:"tags"`
+}
+
+type decodeMeta struct {
+	Tags []string `json:"tags"`
+}
+
+type decodePerson struct {
+	decodeBase
+	*DecodeMeta
+	Name     decodeName          `json:"name"`
+	Age      uint8               `json:"age"`
+	Score    float32             `json:"score"`
+	Friends  []*decodeName       `json:"friends"`
+	Nicks    [2]string           `json:"nicks"`
+	Attrs    map[string]any      `json:"attrs"`
+	Counts   map[int]int         `json:"counts"`
+	Any      any                 `json:"any"`
+	Raw      encjson.RawMessage  `json:"raw"`
+	Number   encjson.Number      `json:"number"`
+	When     time.Time           `json:"when"`
+	Data     []byte              `json:"data"`
+	Optional *string             `json:"optional,omitempty"`
+	Skipped  string              `json:"-"`
+	Upper    string              `json:"UPPER"`
+	ByName   map[decodeKey]bool  `json:"byName"`
+	Nested   map[string][]uint16 `json:"nested"`
+	private  string
+}
+
+type decodeKey string
+
+func (k *decodeKey) UnmarshalText(text []byte) error {
+	*k = decodeKey(strings.ToUpper(string(text)))
+	return nil
+}
+
+func TestDecode(t *testing.T) {
+	json := `{
+		"id": 7,
+		"created": "yesterday",
+		"Hidden": "ambiguous",
+		"tags": ["a", "b"],
+		"name": {"first": "Tom", "last": "Anderson", "middle": "X"},
+		"age": 37,
+		"score": 1.5e0,
+		"friends": [{"first": "Dale"}, null],
+		"nicks": ["t", "tommy", "thomas"],
+		"attrs": {"x": [1, "y", true, null], "z": {}},
+		"counts": {"1": 2, "-3": 4},
+		"any": 12345678901234567890,
+		"raw": [ 1, 2 ],
+		"number": 12345678901234567890,
+		"when": "2014-05-16T08:28:06.989Z",
+		"data": "aGVsbG8=",
+		"optional": "set",
+		"Skipped": "no",
+		"upper": "case-insensitive",
+		"byName": {"k": true},
+		"nested": {"a": [1, 2], "b": []},
+		"private": "no",
+		"unknown": {"ignored": true},
+		"id": 8
+	}`
+
+	var p decodePerson
+	if err := Parse(json).Decode(&p); err != nil {
+		t.Fatal(err)
+	}
+
+	optional := "set"
+	expected := decodePerson{
+		decodeBase: decodeBase{ID: 7, Created: "yesterday"},
+		DecodeMeta: &DecodeMeta{Tags: []string{"a", "b"}},
+		Name:       decodeName{First: "Tom", Last: "Anderson"},
+		Age:        37,
+		Score:      1.5,
+		Friends:    []*decodeName{{First: "Dale"}, nil},
+		Nicks:      [2]string{"t", "tommy"},
+		Attrs:      map[string]any{"x": []any{1.0, "y", true, nil}, "z": map[string]any{}},
+		Counts:     map[int]int{1: 2, -3: 4},
+		Any:        12345678901234567890.0,
+		Raw:        encjson.RawMessage(`[ 1, 2 ]`),
+		Number:     "12345678901234567890",
+		When:       time.Date(2014, 5, 16, 8, 28, 6, 989000000, time.UTC),
+		Data:       []byte("hello"),
+		Optional:   &optional,
+		Upper:      "case-insensitive",
+		ByName:     map[decodeKey]bool{"K": true},
+		Nested:     map[string][]uint16{"a": {1, 2}, "b": {}},
+	}
+	if !reflect.DeepEqual(p, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, p)
+	}
+
+	// decoding matches encoding/json, except for duplicate keys
+	var q decodePerson
+	if err := encjson.Unmarshal([]byte(strings.Replace(json, `"id": 8`, `"id": 7`, 1)), &q); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(p, q) {
+		t.Fatalf("expected %+v, got %+v", q, p)
+	}
+}
+
+func TestDecodeInto(t *testing.T) {
+	// null resets pointers, maps and slices and leaves other values alone
+	s, n, m, x := new(string), 5, map[string]int{"a": 1}, []int{1}
+	var v struct {
+		S *string
+		N int
+		M map[string]int
+		X []int
+	}
+	v.S, v.N, v.M, v.X = s, n, m, x
+	assert(t, Parse(`{"S":null,"N":null,"M":null,"X":null}`).Decode(&v) == nil)
+	assert(t, v.S == nil && v.N == 5 && v.M == nil && v.X == nil)
+
+	// existing maps are added to, and existing values are decoded into
+	m = map[string]int{"a": 1}
+	assert(t, Parse(`{"b":2}`).Decode(&m) == nil)
+	assert(t, reflect.DeepEqual(m, map[string]int{"a": 1, "b": 2}))
+
+	var i any = &decodeName{Last: "Anderson"}
+	assert(t, Parse(`{"first":"Tom"}`).Decode(&i) == nil)
+	assert(t, *i.(*decodeName) == decodeName{First: "Tom", Last: "Anderson"})
+
+	// results from Get decode their own value
+	var name decodeName
+	assert(t, Get([]byte(`{"a":{"b":{"first":"Tom"}}}`), "/a/b").Decode(&name) == nil)
+	assert(t, name.First == "Tom")
+
+	var b bool
+	assert(t, Parse(`true`).Decode(&b) == nil && b)
+	var f float64
+	assert(t, Parse(`-1.25e2`).Decode(&f) == nil && f == -125)
+	var e []int
+	assert(t, Parse(`[]`).Decode(&e) == nil && e != nil && len(e) == 0)
+
+	// a string that holds a valid number decodes into json.Number, as it does
+	// with encoding/json
+	var num, expected struct{ N encjson.Number }
+	assert(t, Parse(`{"N":"-1.5e3"}`).Decode(&num) == nil && num.N == "-1.5e3")
+	assert(t, encjson.Unmarshal([]byte(`{"N":"-1.5e3"}`), &expected) == nil && num == expected)
+}
+
+func TestDecodeErrors(t *testing.T) {
+	type inner struct {
+		Values []int8 `json:"values"`
+	}
+	type outer struct {
+		Items map[string]inner `json:"items"`
+	}
+
+	cases := []struct {
+		json    string
+		v       any
+		pointer string
+		message string
+	}{
+		{`{"items":{"a/b":{"values":[1,2,300]}}}`, new(outer), "/items/a~1b/values/2", "number 300 overflows int8"},
+		{`{"items":{"x":{"values":[1.5]}}}`, new(outer), "/items/x/values/0", "cannot decode JSON number 1.5"},
+		{`{"items":{"x":{"values":"no"}}}`, new(outer), "/items/x/values", "cannot decode JSON string"},
+		{`{"items":[]}`, new(outer), "/items", "cannot decode JSON array"},
+		{`[1, true]`, new([]string), "/0", "cannot decode JSON number 1"},
+		{`{"a":1}`, new(map[bool]int), "", "cannot decode JSON object"},
+		{`{"x":1}`, new(map[int]int), "/x", `invalid map key "x"`},
+		{`"not base64!"`, new([]byte), "", "illegal base64 data at input byte 3"},
+		{`-1`, new(uint), "", "cannot decode JSON number -1"},
+		{`18446744073709551616`, new(uint64), "", "number 18446744073709551616 overflows uint64"},
+		{`1e39`, new(float32), "", "number 1e39 overflows float32"},
+		{`{"when":"yesterday"}`, new(struct{ When time.Time }), "/when", `parsing time "yesterday"`},
+		{`{"when":1}`, new(struct{ When time.Time }), "/when", "into *time.Time"},
+		{`{"tags":[]}`, new(struct{ *decodeMeta }), "/tags", "cannot set embedded pointer to unexported struct"},
+		{`[1,`, new([]int), "", "unexpected end of JSON input"},
+		{`{"n":"1x"}`, new(struct{ N encjson.Number }), "/n", `invalid number literal "1x"`},
+		{`{"n":" 1"}`, new(struct{ N encjson.Number }), "/n", `invalid number literal " 1"`},
+		{`{"n":""}`, new(struct{ N encjson.Number }), "/n", `invalid number literal ""`},
+	}
+	for _, c := range cases {
+		t.Run(c.json, func(t *testing.T) {
+			err := Parse(c.json).Decode(c.v)
+			var derr *DecodeError
+			if !errors.As(err, &derr) {
+				t.Fatalf("expected *DecodeError, got %v", err)
+			}
+			if derr.Pointer != c.pointer {
+				t.Fatalf("expected pointer %q, got %q", c.pointer, derr.Pointer)
+			}
+			if !strings.Contains(err.Error(), c.message) {
+				t.Fatalf("expected %q in %q", c.message, err)
+			}
+		})
+	}
+
+	var v int
+	assert(t, Parse(`1`).Decode(v) != nil)
+	assert(t, Parse(`1`).Decode(nil) != nil)
+	assert(t, Get(`{}`, "/x").Decode(&v) != nil)
+}
+
+func ExampleResult_Decode() {
+	json := `{"name":{"first":"Janet","last":"Prichard"},"age":47}`
+
+	var person struct {
+		Name struct {
+			First string `json:"first"`
+		} `json:"name"`
+		Age int `json:"age"`
+	}
+	if err := Parse(json).Decode(&person); err != nil {
+		panic(err)
+	}
+	fmt.Println(person.Name.First, person.Age)
+	// Output: Janet 47
+}
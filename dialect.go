@@ -0,0 +1,367 @@
+package jp
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Dialect identifies a dialect of JSON that can be read by the *WithOptions
+// functions.
+type Dialect int
+
+const (
+	// DialectJSON is RFC 8259 JSON.
+	DialectJSON Dialect = iota
+	// DialectJSONC is JSON with comments: JSON that may also contain // and
+	// /* */ comments and trailing commas in objects and arrays.
+	DialectJSONC
+	// DialectJSON5 is the subset of JSON5 that can be represented as JSON.
+	// In addition to the extensions of DialectJSONC, it permits unquoted
+	// object keys, single-quoted strings, hexadecimal numbers, numbers with a
+	// leading '+' or a leading or trailing decimal point, line continuations
+	// in strings, and the \x, \v, \0 and \' escape sequences. Infinity and NaN
+	// cannot be represented as JSON and are rejected.
+	DialectJSON5
+)
+
+// String returns the name of the dialect.
+func (d Dialect) String() string {
+	switch d {
+	default:
+		return ""
+	case DialectJSON:
+		return "JSON"
+	case DialectJSONC:
+		return "JSONC"
+	case DialectJSON5:
+		return "JSON5"
+	}
+}
+
+// ParseWithOptions validates json as ValidateWithOptions does and then parses
+// it as Parse does. The result can be queried and iterated like any other
+// Result.
+//
+// If opts specifies a dialect other than DialectJSON, the document is first
+// translated to JSON. The translation of a DialectJSONC document replaces
+// comments and trailing commas with whitespace, so the offsets of values
+// within the result are their offsets within json. The translation of a
+// DialectJSON5 document may change the length of the document, in which case
+// the offsets of values refer to the translated document. Offsets reported in
+// errors always refer to json.
+func ParseWithOptions[T Stringlike](json T, opts *ValidateOptions) (Result[T], error) {
+	json, err := normalize(json, opts)
+	if err != nil {
+		return Result[T]{}, err
+	}
+	return Parse(json), nil
+}
+
+// normalize translates json from the dialect given by opts to JSON and
+// validates the result.
+func normalize[T Stringlike](json T, opts *ValidateOptions) (T, error) {
+	if opts == nil {
+		return json, Validate(json)
+	}
+	if opts.Dialect == DialectJSON {
+		return json, validate(json, *opts)
+	}
+
+	if opts.MaxSize > 0 && len(json) > opts.MaxSize {
+		return json, &LimitError{Limit: SizeLimit, Max: opts.MaxSize, Offset: opts.MaxSize}
+	}
+	n := normalizer[T]{json: json, json5: opts.Dialect == DialectJSON5}
+	normalized := n.normalize()
+
+	limits := *opts
+	limits.MaxSize = 0
+	switch err := validate(normalized, limits).(type) {
+	case nil:
+		return normalized, nil
+	case *SyntaxError:
+		return json, newSyntaxError(json, n.inputOffset(err.Offset), err.msg)
+	case *LimitError:
+		err.Offset = n.inputOffset(err.Offset)
+		return json, err
+	default:
+		return json, err
+	}
+}
+
+// normalizer translates JSONC and JSON5 to JSON. The translation is lexical:
+// the normalizer rewrites the constructs that are not JSON and leaves the rest
+// of the document as-is for the validator to check.
+type normalizer[T Stringlike] struct {
+	json  T
+	json5 bool
+
+	// buf holds the translated document up to the input offset copied. It is
+	// nil if the document has not been changed.
+	buf    []byte
+	copied int
+	// edits records the output and input offsets of the end of each
+	// replacement, in order.
+	edits []normalizerEdit
+}
+
+type normalizerEdit struct {
+	out, in int
+}
+
+// normalize returns the translated document.
+func (n *normalizer[T]) normalize() T {
+	json := n.json
+	// prev is the last byte before i that is neither whitespace nor part of a
+	// comment
+	var prev byte
+	for i := 0; i < len(json); {
+		c := json[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+			continue
+		case c == '"' || c == '\'' && n.json5:
+			i = n.string(i)
+		case c == '/':
+			end := n.comment(i)
+			if end == i {
+				i++
+				break
+			}
+			n.blank(i, end)
+			i = end
+			continue
+		case c == ',':
+			// a trailing comma is only removed if it follows a member or
+			// element
+			if prev != '{' && prev != '[' && prev != ',' {
+				if j := n.skip(i + 1); j < len(json) && (json[j] == '}' || json[j] == ']') {
+					n.blank(i, i+1)
+				}
+			}
+			i++
+		case !n.json5:
+			i++
+		case isIdentifierStart(c):
+			i = n.identifier(i)
+		case c == '+' || c == '-' || c == '.' || c >= '0' && c <= '9':
+			i = n.number(i)
+		default:
+			i++
+		}
+		prev = c
+	}
+	if n.buf == nil {
+		return json
+	}
+	n.buf = append(n.buf, json[n.copied:]...)
+	return T(n.buf)
+}
+
+// replace replaces json[start:end] with s.
+func (n *normalizer[T]) replace(start, end int, s string) {
+	if n.buf == nil {
+		n.buf = make([]byte, 0, len(n.json)+len(n.json)/8)
+	}
+	n.buf = append(n.buf, n.json[n.copied:start]...)
+	n.buf = append(n.buf, s...)
+	n.copied = end
+	n.edits = append(n.edits, normalizerEdit{out: len(n.buf), in: end})
+}
+
+// blank replaces json[start:end] with whitespace, preserving newlines.
+func (n *normalizer[T]) blank(start, end int) {
+	var b strings.Builder
+	b.Grow(end - start)
+	for i := start; i < end; i++ {
+		if n.json[i] == '\n' {
+			b.WriteByte('\n')
+		} else {
+			b.WriteByte(' ')
+		}
+	}
+	n.replace(start, end, b.String())
+}
+
+// inputOffset maps an offset within the translated document to the
+// corresponding offset within the input.
+func (n *normalizer[T]) inputOffset(out int) int {
+	in := out
+	for _, e := range n.edits {
+		if e.out > out {
+			break
+		}
+		in = e.in + out - e.out
+	}
+	return in
+}
+
+// comment returns the offset just past the comment that begins at offset i,
+// or i if there is no well-formed comment at i.
+func (n *normalizer[T]) comment(i int) int {
+	json := n.json
+	if i+1 >= len(json) || json[i] != '/' {
+		return i
+	}
+	switch json[i+1] {
+	case '/':
+		j := i + 2
+		for ; j < len(json) && json[j] != '\n'; j++ {
+		}
+		return j
+	case '*':
+		for j := i + 2; j+1 < len(json); j++ {
+			if json[j] == '*' && json[j+1] == '/' {
+				return j + 2
+			}
+		}
+	}
+	return i
+}
+
+// skip returns the offset of the first byte at or after offset i that is
+// neither whitespace nor part of a comment.
+func (n *normalizer[T]) skip(i int) int {
+	json := n.json
+	for i < len(json) {
+		switch json[i] {
+		case ' ', '\t', '\n', '\r':
+			i++
+		case '/':
+			end := n.comment(i)
+			if end == i {
+				return i
+			}
+			i = end
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// string translates the string that begins at offset i and returns the
+// offset just past its end.
+func (n *normalizer[T]) string(i int) int {
+	json := n.json
+	quote := json[i]
+	if quote == '\'' {
+		n.replace(i, i+1, `"`)
+	}
+	for j := i + 1; j < len(json); {
+		switch c := json[j]; {
+		case c == quote:
+			if quote == '\'' {
+				n.replace(j, j+1, `"`)
+			}
+			return j + 1
+		case c == '"':
+			n.replace(j, j+1, `\"`)
+			j++
+		case c == '\\' && j+1 < len(json):
+			j = n.escape(j)
+		default:
+			j++
+		}
+	}
+	return len(json)
+}
+
+// escape translates the escape sequence that begins at offset i and returns
+// the offset just past its end.
+func (n *normalizer[T]) escape(i int) int {
+	json := n.json
+	if !n.json5 {
+		return i + 2
+	}
+	switch json[i+1] {
+	case '\'':
+		n.replace(i, i+2, "'")
+	case '\n':
+		n.replace(i, i+2, "")
+	case '\r':
+		if i+2 < len(json) && json[i+2] == '\n' {
+			n.replace(i, i+3, "")
+			return i + 3
+		}
+		n.replace(i, i+2, "")
+	case 'v':
+		n.replace(i, i+2, `\u000b`)
+	case '0':
+		if i+2 < len(json) && json[i+2] >= '0' && json[i+2] <= '9' {
+			break
+		}
+		n.replace(i, i+2, `\u0000`)
+	case 'x':
+		if i+4 <= len(json) && isHexDigit(json[i+2]) && isHexDigit(json[i+3]) {
+			n.replace(i, i+4, `\u00`+string(json[i+2:i+4]))
+			return i + 4
+		}
+	}
+	return i + 2
+}
+
+// identifier translates the identifier that begins at offset i and returns
+// the offset just past its end. Identifiers that are followed by a colon are
+// object keys, and are quoted.
+func (n *normalizer[T]) identifier(i int) int {
+	json := n.json
+	end := i + 1
+	for ; end < len(json) && (isIdentifierStart(json[end]) || json[end] >= '0' && json[end] <= '9'); end++ {
+	}
+	if j := n.skip(end); j < len(json) && json[j] == ':' {
+		n.replace(i, end, `"`+string(json[i:end])+`"`)
+	}
+	return end
+}
+
+// number translates the number that begins at offset i and returns the
+// offset just past its end.
+func (n *normalizer[T]) number(i int) int {
+	json := n.json
+	end := i + 1
+	for ; end < len(json) && isNumberByte(json[end]); end++ {
+	}
+
+	j := i
+	if json[j] == '+' || json[j] == '-' {
+		j++
+	}
+	if j+1 < end && json[j] == '0' && (json[j+1] == 'x' || json[j+1] == 'X') {
+		v, err := strconv.ParseUint(string(json[j+2:end]), 16, 64)
+		if err != nil {
+			return end
+		}
+		s := strconv.FormatUint(v, 10)
+		if json[i] == '-' {
+			s = "-" + s
+		}
+		n.replace(i, end, s)
+		return end
+	}
+
+	if json[i] == '+' {
+		n.replace(i, i+1, "")
+	}
+	if j < end && json[j] == '.' {
+		n.replace(j, j, "0")
+	}
+	for ; j < end && json[j] >= '0' && json[j] <= '9'; j++ {
+	}
+	if j < end && json[j] == '.' && (j+1 == end || json[j+1] < '0' || json[j+1] > '9') && j > i && json[j-1] >= '0' && json[j-1] <= '9' {
+		n.replace(j, j+1, "")
+	}
+	return end
+}
+
+func isIdentifierStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c == '$'
+}
+
+func isNumberByte(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '.' || c == '+' || c == '-'
+}
+
+func isHexDigit(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
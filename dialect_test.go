@@ -0,0 +1,137 @@
+package jp
+
+import (
+	"errors"
+	"testing"
+)
+
+const jsoncConfig = `// server configuration
+{
+	"name": "api", // the service name
+	/* listeners, in order
+	   of preference */
+	"listen": [
+		"127.0.0.1:80",
+		"[::1]:80", // IPv6
+	],
+	"path": "/a//b/*c*/",
+}
+`
+
+func TestParseWithOptionsJSONC(t *testing.T) {
+	opts := &ValidateOptions{Dialect: DialectJSONC}
+	if Valid(jsoncConfig) {
+		t.Fatal("expected JSONC to be invalid JSON")
+	}
+	if err := ValidateWithOptions(jsoncConfig, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := ParseWithOptions(jsoncConfig, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(value.Raw) != len(jsoncConfig)-len("// server configuration\n")-1 {
+		t.Fatalf("unexpected length %d", len(value.Raw))
+	}
+
+	var elements []string
+	for it := value.Get("/listen").Range(); it.Next(); {
+		elements = append(elements, it.Value().Str)
+		if v := it.Value(); jsoncConfig[v.Index:v.Index+len(v.Raw)] != v.Raw {
+			t.Fatalf("index %d does not refer to the input", v.Index)
+		}
+	}
+	if len(elements) != 2 || elements[0] != "127.0.0.1:80" || elements[1] != "[::1]:80" {
+		t.Fatalf("unexpected elements %v", elements)
+	}
+	if value.Get("/listen").Len() != 2 {
+		t.Fatalf("unexpected length %d", value.Get("/listen").Len())
+	}
+
+	path, err := GetWithOptions(jsoncConfig, "/path", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path.Str != "/a//b/*c*/" {
+		t.Fatalf("unexpected path %q", path.Str)
+	}
+
+	// comments are not accepted by default
+	if _, err := ParseWithOptions(jsoncConfig, nil); err == nil {
+		t.Fatal("expected error")
+	}
+	// JSON5 extensions are not accepted as JSONC
+	if err := ValidateWithOptions(`{a: 1}`, opts); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestParseWithOptionsJSON5(t *testing.T) {
+	tests := []struct {
+		json5, json string
+	}{
+		{`{a: 1, $b_2: 2, true: 3,}`, `{"a":1,"$b_2":2,"true":3}`},
+		{`['single', 'it\'s "quoted"']`, `["single","it's \"quoted\""]`},
+		{`"it\'s"`, `"it's"`},
+		{`[0x1F, -0xff, +0X10, +1, .5, -.5, 5., 1.e3]`, `[31,-255,16,1,0.5,-0.5,5,1e3]`},
+		{`'\x41\v\0 line \
+continued'`, `"A\u000b\u0000 line continued"`},
+		{`{ // comment
+	key: /* comment */ 'value', }`, `{"key":"value"}`},
+		{`[true, false, null]`, `[true,false,null]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.json5, func(t *testing.T) {
+			value, err := ParseWithOptions(tt.json5, &ValidateOptions{Dialect: DialectJSON5})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !value.Equal(Parse(tt.json)) {
+				t.Fatalf("expected %v, got %v", tt.json, value.Raw)
+			}
+		})
+	}
+}
+
+func TestParseWithOptionsDialectErrors(t *testing.T) {
+	tests := []struct {
+		json    string
+		dialect Dialect
+		offset  int
+	}{
+		{"// comment\n[1 2]", DialectJSONC, 14},
+		{"[1, /* unterminated", DialectJSONC, 4},
+		{"[1,,]", DialectJSONC, 3},
+		{"[,]", DialectJSONC, 1},
+		{"{,}", DialectJSONC, 1},
+		{`{"a":[,]}`, DialectJSONC, 6},
+		{"[ /* c */ , ]", DialectJSONC, 10},
+		{"[,]", DialectJSON5, 1},
+		{"{,}", DialectJSON5, 1},
+		{`{a:[ , ]}`, DialectJSON5, 5},
+		{`{a:1,,}`, DialectJSON5, 5},
+		{"{a: 1, 'b': 2 3}", DialectJSON5, 14},
+		{"[Infinity]", DialectJSON5, 1},
+		{"[-NaN]", DialectJSON5, 2},
+		{"{a b: 1}", DialectJSON5, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.json, func(t *testing.T) {
+			_, err := ParseWithOptions(tt.json, &ValidateOptions{Dialect: tt.dialect})
+			var serr *SyntaxError
+			if !errors.As(err, &serr) {
+				t.Fatalf("expected *SyntaxError, got %v", err)
+			}
+			if serr.Offset != tt.offset {
+				t.Fatalf("expected offset %d, got %v", tt.offset, serr)
+			}
+		})
+	}
+
+	_, err := ParseWithOptions(`{a: {b: {c: 1}}}`, &ValidateOptions{Dialect: DialectJSON5, MaxDepth: 2})
+	var lerr *LimitError
+	if !errors.As(err, &lerr) || lerr.Limit != DepthLimit || lerr.Offset != 8 {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
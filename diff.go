@@ -0,0 +1,208 @@
+package jp
+
+import (
+	"strconv"
+	"strings"
+)
+
+// PatchOp is a single RFC 6902 JSON Patch operation.
+type PatchOp struct {
+	// Op is the name of the operation, e.g. "add", "remove" or "replace".
+	Op string
+	// Path is the RFC 6901 JSON pointer that the operation applies to.
+	Path string
+	// From is the source pointer of a "move" or "copy" operation.
+	From string
+	// Value is the raw JSON value of an "add", "replace" or "test" operation.
+	Value []byte
+}
+
+// DiffOptions controls how DiffWith compares documents.
+type DiffOptions struct {
+	// LCS enables a longest common subsequence comparison of arrays, so that
+	// inserting or removing elements in the middle of an array produces a
+	// single add or remove operation rather than replacing every following
+	// element. The comparison takes time proportional to the product of the
+	// lengths of the arrays.
+	LCS bool
+}
+
+// Diff returns a patch that transforms a into b. The patch is made up of add,
+// remove and replace operations whose paths are RFC 6901 JSON pointers.
+// Objects are compared member by member regardless of the order of their
+// members, and arrays are compared element by element.
+//
+// Values are compared semantically, as they would be by a JSON Patch "test"
+// operation, so a and b may be formatted differently. The patch can be
+// applied with ApplyPatch after serializing it with MarshalPatch.
+func Diff[T Stringlike](a, b T) []PatchOp {
+	return DiffWith(a, b, DiffOptions{})
+}
+
+// DiffWith is like Diff, but accepts options that control how the documents
+// are compared.
+func DiffWith[T Stringlike](a, b T, options DiffOptions) []PatchOp {
+	d := differ{options: options}
+	diffValues(&d, "", Parse(a), Parse(b))
+	return d.ops
+}
+
+// differ accumulates the operations of a patch.
+type differ struct {
+	options DiffOptions
+	ops     []PatchOp
+}
+
+func (d *differ) add(path string, value []byte) {
+	d.ops = append(d.ops, PatchOp{Op: "add", Path: path, Value: value})
+}
+
+func (d *differ) remove(path string) {
+	d.ops = append(d.ops, PatchOp{Op: "remove", Path: path})
+}
+
+func (d *differ) replace(path string, value []byte) {
+	d.ops = append(d.ops, PatchOp{Op: "replace", Path: path, Value: value})
+}
+
+func diffValues[T Stringlike](d *differ, path string, a, b Result[T]) {
+	switch {
+	case equal(a, b):
+	case a.IsObject() && b.IsObject():
+		diffObjects(d, path, a, b)
+	case a.IsArray() && b.IsArray():
+		if d.options.LCS {
+			diffArraysLCS(d, path, a.Array(), b.Array())
+		} else {
+			diffArrays(d, path, a.Array(), b.Array())
+		}
+	default:
+		d.replace(path, []byte(b.Raw))
+	}
+}
+
+func diffObjects[T Stringlike](d *differ, path string, a, b Result[T]) {
+	am, bm := a.Map(), b.Map()
+
+	// remove or update the members of a, then add the members of b
+	seen := map[string]bool{}
+	for it := a.Range(); it.Next(); {
+		key := it.Key().Str
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if bv, ok := bm[key]; ok {
+			diffValues(d, appendPointer(path, key), am[key], bv)
+		} else {
+			d.remove(appendPointer(path, key))
+		}
+	}
+	for it := b.Range(); it.Next(); {
+		key := it.Key().Str
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		d.add(appendPointer(path, key), []byte(bm[key].Raw))
+	}
+}
+
+func diffArrays[T Stringlike](d *differ, path string, a, b []Result[T]) {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	for i := 0; i < n; i++ {
+		diffValues(d, appendPointer(path, strconv.Itoa(i)), a[i], b[i])
+	}
+	for i := n; i < len(b); i++ {
+		d.add(appendPointer(path, strconv.Itoa(i)), []byte(b[i].Raw))
+	}
+	// remove trailing elements from the end so that indices remain valid
+	for i := len(a) - 1; i >= n; i-- {
+		d.remove(appendPointer(path, strconv.Itoa(i)))
+	}
+}
+
+func diffArraysLCS[T Stringlike](d *differ, path string, a, b []Result[T]) {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and
+	// b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case equal(a[i], b[j]):
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	// k is the index of the current element in the partially-patched array
+	i, j, k := 0, 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case equal(a[i], b[j]):
+			i, j, k = i+1, j+1, k+1
+		case lcs[i+1][j+1] == lcs[i][j]:
+			// neither element is part of the common subsequence, so update
+			// one into the other in place
+			diffValues(d, appendPointer(path, strconv.Itoa(k)), a[i], b[j])
+			i, j, k = i+1, j+1, k+1
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			d.remove(appendPointer(path, strconv.Itoa(k)))
+			i++
+		default:
+			d.add(appendPointer(path, strconv.Itoa(k)), []byte(b[j].Raw))
+			j, k = j+1, k+1
+		}
+	}
+	for ; i < len(a); i++ {
+		d.remove(appendPointer(path, strconv.Itoa(k)))
+	}
+	for ; j < len(b); j, k = j+1, k+1 {
+		d.add(appendPointer(path, strconv.Itoa(k)), []byte(b[j].Raw))
+	}
+}
+
+// appendPointer returns the pointer formed by appending token to path.
+func appendPointer(path, token string) string {
+	var b strings.Builder
+	b.Grow(len(path) + len(token) + 1)
+	b.WriteString(path)
+	b.WriteByte('/')
+	writeEscapedToken(&b, token)
+	return b.String()
+}
+
+// MarshalPatch serializes a patch as an RFC 6902 JSON Patch document.
+func MarshalPatch(ops []PatchOp) []byte {
+	buf := []byte{'['}
+	for i, op := range ops {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, `{"op":`...)
+		buf = appendQuoted(buf, op.Op)
+		if op.From != "" || op.Op == "move" || op.Op == "copy" {
+			buf = append(buf, `,"from":`...)
+			buf = appendQuoted(buf, op.From)
+		}
+		buf = append(buf, `,"path":`...)
+		buf = appendQuoted(buf, op.Path)
+		if op.Value != nil {
+			buf = append(buf, `,"value":`...)
+			buf = append(buf, op.Value...)
+		}
+		buf = append(buf, '}')
+	}
+	return append(buf, ']')
+}
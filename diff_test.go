@@ -0,0 +1,89 @@
+package jp
+
+import (
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	cases := []struct {
+		a        string
+		b        string
+		expected string
+	}{
+		{`{"a":1}`, `{ "a" : 1.0 }`, `[]`},
+		{`{"a":1,"b":2}`, `{"b":2,"a":3}`, `[{"op":"replace","path":"/a","value":3}]`},
+		{`{"a":1,"b":2}`, `{"b":2,"c":3}`, `[{"op":"remove","path":"/a"},{"op":"add","path":"/c","value":3}]`},
+		{`{"a":{"b":{"c":1}}}`, `{"a":{"b":{"c":2}}}`, `[{"op":"replace","path":"/a/b/c","value":2}]`},
+		{`{"a/b":1,"m~n":2}`, `{"a/b":2}`, `[{"op":"replace","path":"/a~1b","value":2},{"op":"remove","path":"/m~0n"}]`},
+		{`[1,2,3]`, `[1,2,3,4,5]`, `[{"op":"add","path":"/3","value":4},{"op":"add","path":"/4","value":5}]`},
+		{`[1,2,3,4]`, `[1,2]`, `[{"op":"remove","path":"/3"},{"op":"remove","path":"/2"}]`},
+		{`[1,2,3]`, `[1,9,2,3]`, `[{"op":"replace","path":"/1","value":9},{"op":"replace","path":"/2","value":2},{"op":"add","path":"/3","value":3}]`},
+		{`{"a":[1]}`, `{"a":{"0":1}}`, `[{"op":"replace","path":"/a","value":{"0":1}}]`},
+		{`1`, `"one"`, `[{"op":"replace","path":"","value":"one"}]`},
+	}
+	for _, c := range cases {
+		t.Run(c.a+c.b, func(t *testing.T) {
+			actual := string(MarshalPatch(Diff(c.a, c.b)))
+			if actual != c.expected {
+				t.Fatalf("expected %s, got %s", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestDiffLCS(t *testing.T) {
+	cases := []struct {
+		a        string
+		b        string
+		expected string
+	}{
+		{`[1,2,3]`, `[1,9,2,3]`, `[{"op":"add","path":"/1","value":9}]`},
+		{`[1,2,3,4]`, `[1,3,4]`, `[{"op":"remove","path":"/1"}]`},
+		{`[1,2,3]`, `[0,1,3,4]`, `[{"op":"add","path":"/0","value":0},{"op":"remove","path":"/2"},{"op":"add","path":"/3","value":4}]`},
+		{`[{"a":1},2]`, `[{"a":2},2]`, `[{"op":"replace","path":"/0/a","value":2}]`},
+		{`[]`, `[1,2]`, `[{"op":"add","path":"/0","value":1},{"op":"add","path":"/1","value":2}]`},
+		{`[1,2]`, `[]`, `[{"op":"remove","path":"/0"},{"op":"remove","path":"/0"}]`},
+	}
+	for _, c := range cases {
+		t.Run(c.a+c.b, func(t *testing.T) {
+			actual := string(MarshalPatch(DiffWith(c.a, c.b, DiffOptions{LCS: true})))
+			if actual != c.expected {
+				t.Fatalf("expected %s, got %s", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestDiffRoundTrip(t *testing.T) {
+	docs := []string{
+		exampleJSON,
+		`{"name":{"first":"Tom","last":"Anderson"},"children":["Sara","Alex","Jack"],"friends":[{"first":"Dale"},{"first":"Roger"}]}`,
+		`{"name":{"first":"Tom"},"children":["Alex","Zoe","Jack"],"friends":[{"first":"Roger","last":"Craig"}],"age":37}`,
+		`[1,[2,[3,[4]]],{"a":"b"}]`,
+	}
+	for _, lcs := range []bool{false, true} {
+		for _, a := range docs {
+			for _, b := range docs {
+				patch := MarshalPatch(DiffWith(a, b, DiffOptions{LCS: lcs}))
+				actual, err := ApplyPatch(a, string(patch))
+				if err != nil {
+					t.Fatalf("applying %s: %v", patch, err)
+				}
+				if !equal(Parse(actual), Parse(b)) {
+					t.Fatalf("applying %s: expected %s, got %s", patch, b, actual)
+				}
+			}
+		}
+	}
+}
+
+func TestMarshalPatch(t *testing.T) {
+	ops := []PatchOp{
+		{Op: "move", From: "/a", Path: "/b"},
+		{Op: "test", Path: "/b", Value: []byte(`"x\"y"`)},
+		{Op: "remove", Path: "/c"},
+	}
+	expected := `[{"op":"move","from":"/a","path":"/b"},{"op":"test","path":"/b","value":"x\"y"},{"op":"remove","path":"/c"}]`
+	assert(t, string(MarshalPatch(ops)) == expected)
+	assert(t, string(MarshalPatch(nil)) == `[]`)
+}
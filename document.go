@@ -0,0 +1,194 @@
+package jp
+
+// Document is a structural index of a JSON document. The index records the
+// position of every value in the document, the members of every object and
+// the elements of every array, so that pointers can be resolved against the
+// document repeatedly without rescanning it: each reference token is resolved
+// in constant time for arrays and small or indexed objects.
+//
+// A Document is safe for concurrent use.
+type Document[T Stringlike] struct {
+	json T
+	// nodes holds the document's values in document order. The root value is
+	// node 0.
+	nodes []documentNode
+	// children holds the node IDs of the members and elements of each
+	// container. The children of a container are contiguous.
+	children []int
+	// keys holds the unescaped key of each entry in children, or the empty
+	// string for array elements.
+	keys []string
+
+	// stack and stackKeys hold the children of the containers that are being
+	// built.
+	stack     []int
+	stackKeys []string
+}
+
+// documentNode describes a single value in a Document.
+type documentNode struct {
+	// start and end are the offsets of the value within the document.
+	start, end int
+	// first is the offset in the document's children of the value's first
+	// member or element, and count is the number of members or elements.
+	first, count int
+	// byKey maps keys to member positions for objects with many members.
+	byKey map[string]int
+}
+
+// documentIndexThreshold is the number of members at which an object's
+// members are indexed by a map rather than searched linearly.
+const documentIndexThreshold = 8
+
+// NewDocument builds a structural index of json. If json is not valid, the
+// returned error is a *SyntaxError.
+func NewDocument[T Stringlike](json T) (*Document[T], error) {
+	if err := Validate(json); err != nil {
+		return nil, err
+	}
+	d := &Document[T]{json: json}
+	d.build(0)
+	d.stack, d.stackKeys = nil, nil
+	return d, nil
+}
+
+// Root returns the document's root value.
+func (d *Document[T]) Root() Result[T] {
+	return d.result(0)
+}
+
+// Get searches the document for the specified pointer. The pointer is
+// interpreted as it would be by Get.
+func (d *Document[T]) Get(pointer string) Result[T] {
+	var buf [8]referenceToken
+	tokens, _ := appendReferenceTokens(buf[:0], pointer)
+	return d.GetPointer(Pointer{tokens: tokens})
+}
+
+// GetPointer searches the document for the value referred to by the pointer.
+// As with Get, if an object has several members with the same key, each is
+// tried in turn until one of them resolves the rest of the pointer.
+func (d *Document[T]) GetPointer(p Pointer) Result[T] {
+	id, ok := d.lookup(0, p.tokens, p.strict)
+	if !ok {
+		return Result[T]{}
+	}
+	return d.result(id)
+}
+
+// lookup resolves tokens against the node id. It returns the ID of the node
+// that the tokens refer to.
+func (d *Document[T]) lookup(id int, tokens []referenceToken, strict bool) (int, bool) {
+	if len(tokens) == 0 {
+		return id, true
+	}
+	t, n := tokens[0], &d.nodes[id]
+	switch d.json[n.start] {
+	case '{':
+		i := 0
+		if n.byKey != nil {
+			var ok bool
+			if i, ok = n.byKey[t.key]; !ok {
+				return -1, false
+			}
+		}
+		for ; i < n.count; i++ {
+			if d.keys[n.first+i] != t.key {
+				continue
+			}
+			if id, ok := d.lookup(d.children[n.first+i], tokens[1:], strict); ok {
+				return id, true
+			}
+		}
+		return -1, false
+	case '[':
+		if t.index == -1 {
+			// as with Get, tokens that are not array indices refer to the
+			// entire array
+			if strict {
+				return -1, false
+			}
+			return id, true
+		}
+		if t.index >= n.count {
+			return -1, false
+		}
+		return d.lookup(d.children[n.first+t.index], tokens[1:], strict)
+	default:
+		return -1, false
+	}
+}
+
+// result returns the value of the node with the given ID.
+func (d *Document[T]) result(id int) Result[T] {
+	n := &d.nodes[id]
+	if c := d.json[n.start]; c == '{' || c == '[' {
+		return Result[T]{Type: JSON, Raw: d.json[n.start:n.end], Index: n.start, len: n.count}
+	}
+	value, _ := parseValueAt(d.json, n.start)
+	return value
+}
+
+// build indexes the value that begins at or after i, and returns the offset
+// just past its end. The document must be valid.
+func (d *Document[T]) build(i int) int {
+	json := d.json
+	for ; json[i] <= ' '; i++ {
+	}
+
+	id := len(d.nodes)
+	d.nodes = append(d.nodes, documentNode{start: i})
+	switch c := json[i]; c {
+	case '{', '[':
+		mark := len(d.stack)
+		for i++; ; {
+			for ; json[i] <= ' ' || json[i] == ','; i++ {
+			}
+			if json[i] == '}' || json[i] == ']' {
+				i++
+				break
+			}
+
+			var key string
+			if c == '{' {
+				var raw T
+				var esc bool
+				i, raw, esc, _ = parseString(json, i+1)
+				raw = raw[1 : len(raw)-1]
+				if esc {
+					key = unescape(raw)
+				} else {
+					key = string(raw)
+				}
+				for ; json[i] != ':'; i++ {
+				}
+				i++
+			}
+
+			d.stack = append(d.stack, len(d.nodes))
+			d.stackKeys = append(d.stackKeys, key)
+			i = d.build(i)
+		}
+
+		n := &d.nodes[id]
+		n.first, n.count = len(d.children), len(d.stack)-mark
+		d.children = append(d.children, d.stack[mark:]...)
+		d.keys = append(d.keys, d.stackKeys[mark:]...)
+		d.stack, d.stackKeys = d.stack[:mark], d.stackKeys[:mark]
+
+		if c == '{' && n.count >= documentIndexThreshold {
+			n.byKey = make(map[string]int, n.count)
+			for j := n.count - 1; j >= 0; j-- {
+				n.byKey[d.keys[n.first+j]] = j
+			}
+		}
+	case '"':
+		i, _, _, _ = parseString(json, i+1)
+	case 't', 'f', 'n':
+		i, _ = parseLiteral(json, i)
+	default:
+		i, _ = parseNumber(json, i)
+	}
+	d.nodes[id].end = i
+	return i
+}
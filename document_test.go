@@ -0,0 +1,165 @@
+package jp
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDocument(t *testing.T) {
+	json := `  {"name":{"first":"Janet","last":"Prichard","abc":1},
+		"age":47, "tags":["a", "b", {"x":[1,2,3]}],"dup":1,"dup":2,
+		"empty":{},"none":[],"t":true,"f":false,"n":null,"neg":-1.5e3}  `
+	pointers := []string{
+		"",
+		"/name",
+		"/name/first",
+		"/name/abc",
+		"/name/middle",
+		"/age",
+		"/age/0",
+		"/tags",
+		"/tags/1",
+		"/tags/2/x/2",
+		"/tags/3",
+		"/tags/x",
+		"/tags/x/y",
+		"//tags//2/",
+		"/dup",
+		"/empty",
+		"/empty/a",
+		"/none",
+		"/none/0",
+		"/t",
+		"/f",
+		"/n",
+		"/neg",
+	}
+
+	doc, err := NewDocument(json)
+	if err != nil {
+		t.Fatal(err)
+	}
+	docBytes, err := NewDocument([]byte(json))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pointer := range pointers {
+		t.Run(pointer, func(t *testing.T) {
+			expected := Get(json, pointer)
+			actual := doc.Get(pointer)
+			if actual.Raw != expected.Raw || actual.Type != expected.Type || actual.Str != expected.Str ||
+				actual.Num != expected.Num || actual.Index != expected.Index {
+				t.Fatalf("expected %#v, got %#v", expected, actual)
+			}
+			if actual.IsObject() || actual.IsArray() {
+				n := 0
+				for it := actual.Range(); it.Next(); {
+					n++
+				}
+				assert(t, actual.Len() == n)
+			}
+
+			actualBytes := docBytes.Get(pointer)
+			if string(actualBytes.Raw) != string(expected.Raw) || actualBytes.Index != expected.Index {
+				t.Fatalf("expected %#v, got %#v", expected, actualBytes)
+			}
+		})
+	}
+
+	assert(t, doc.Root().Raw == strings.TrimSpace(json))
+	assert(t, doc.Get("/tags").Len() == 3)
+	assert(t, doc.Get("/dup").Int() == 1)
+
+	p, err := ParsePointerStrict("/tags/x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, !doc.GetPointer(p).Exists())
+
+	_, err = NewDocument(`{"a":`)
+	assert(t, err != nil)
+}
+
+func TestDocumentLargeObject(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("{")
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&b, `"k%d":{"v":%d},`, i, i)
+	}
+	b.WriteString(`"k0":{"v":-1}}`)
+	json := b.String()
+
+	doc, err := NewDocument(json)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, doc.Root().Len() == 101)
+	for i := 0; i < 100; i++ {
+		pointer := fmt.Sprintf("/k%d/v", i)
+		actual := doc.Get(pointer)
+		assert(t, actual.Int() == int64(i))
+		assert(t, actual.Index == Get(json, pointer).Index)
+	}
+	assert(t, !doc.Get("/k100").Exists())
+}
+
+func TestDocumentDuplicateKeys(t *testing.T) {
+	var large strings.Builder
+	large.WriteString(`{"a":{"c":1},`)
+	for i := 0; i < documentIndexThreshold; i++ {
+		fmt.Fprintf(&large, `"k%d":%d,`, i, i)
+	}
+	large.WriteString(`"a":[5],"a":{"d":{"e":2}}}`)
+
+	docs := []string{
+		`{"a":{"c":1},"a":[5]}`,
+		`{"a":{"c":1},"a":[5],"a":{"d":{"e":2}}}`,
+		`{"a":1,"a":{"b":2},"x":[{"y":1},{"y":2,"y":{"z":3}}]}`,
+		large.String(),
+	}
+	pointers := []string{"/a", "/a/0", "/a/c", "/a/d/e", "/a/b", "/a/1", "/x/1/y/z", "/x/1/y", "/a/x"}
+	for _, json := range docs {
+		doc, err := NewDocument(json)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, pointer := range pointers {
+			expected, actual := Get(json, pointer), doc.Get(pointer)
+			if actual.Raw != expected.Raw || actual.Index != expected.Index {
+				t.Fatalf("%s %s: expected %#v, got %#v", json, pointer, expected, actual)
+			}
+		}
+	}
+
+	doc, err := NewDocument(`{"a":{"c":1},"a":[5]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, doc.Get("/a/0").Int() == 5)
+	assert(t, doc.Get("/a/c").Int() == 1)
+}
+
+func TestDocumentExample(t *testing.T) {
+	doc, err := NewDocument(exampleJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pointer := range []string{"/widget/window/name", "/widget/image/hOffset", "/widget/text/onMouseUp", "/widget/text"} {
+		expected, actual := Get(exampleJSON, pointer), doc.Get(pointer)
+		if actual != expected {
+			t.Fatalf("expected %#v, got %#v", expected, actual)
+		}
+	}
+}
+
+func BenchmarkDocumentGet(b *testing.B) {
+	doc, err := NewDocument(exampleJSON)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		doc.Get("/widget/text/onMouseUp")
+	}
+}
@@ -0,0 +1,567 @@
+package jp
+
+import (
+	encjson "encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+)
+
+// location describes the position of the value referred to by a pointer
+// within a document, or the position at which it would be inserted if it does
+// not exist.
+type location[T Stringlike] struct {
+	// found is true if the value exists.
+	found bool
+	// depth is the position of the first reference token that could not be
+	// resolved, or the number of reference tokens if the value exists.
+	depth int
+
+	// parent is the object or array that contains the value (or that would
+	// contain it, if it does not exist). parent does not exist if the pointer
+	// refers to the whole document.
+	parent Result[T]
+	// length is the number of members or elements in parent.
+	length int
+
+	// value is the value referred to by the pointer.
+	value Result[T]
+	// key is the value's key if parent is an object.
+	key Result[T]
+	// index is the value's index if parent is an array. If the value does not
+	// exist, index is the length of the array.
+	index int
+
+	// prevEnd is the offset just past the end of the preceding member or
+	// element, or -1 if the value is first.
+	prevEnd int
+	// nextStart is the offset of the start of the following member or
+	// element, or -1 if the value is last.
+	nextStart int
+}
+
+// start returns the offset of the start of the value's member or element.
+func (l *location[T]) start() int {
+	if l.parent.IsObject() {
+		return l.key.Index
+	}
+	return l.value.Index
+}
+
+// end returns the offset just past the end of the value.
+func (l *location[T]) end() int {
+	return l.value.Index + len(l.value.Raw)
+}
+
+// parseValueAt parses the value that begins at or after offset i. Unlike the
+// result of parseAny, the returned result always has an accurate Index.
+func parseValueAt[T Stringlike](json T, i int) (Result[T], bool) {
+	for ; i < len(json) && json[i] <= ' '; i++ {
+	}
+	_, value, ok := parseAny(json, i, true)
+	value.Index = i
+	return value, ok
+}
+
+// locate resolves p against json and describes the location of the value it
+// refers to. If the value does not exist, the returned location describes the
+// deepest container that does exist. An error is returned if the pointer
+// cannot refer to a value in the document, e.g. because a reference token is
+// applied to a string or names an array element past the end of the array.
+func locate[T Stringlike](json T, p Pointer) (location[T], *PointerError) {
+	loc := location[T]{prevEnd: -1, nextStart: -1}
+
+	value, ok := parseValueAt(json, 0)
+	if !ok {
+		return loc, &PointerError{Kind: MalformedJSON, Offset: value.Index}
+	}
+
+	for depth, token := range p.tokens {
+		loc.parent, loc.length, loc.depth = value, 0, depth
+		loc.value, loc.key, loc.prevEnd, loc.nextStart = Result[T]{}, Result[T]{}, -1, -1
+
+		fail := func(kind PointerErrorKind, offset int) (location[T], *PointerError) {
+			return loc, &PointerError{Kind: kind, Token: token.key, TokenIndex: depth, Offset: offset, Type: value.Type}
+		}
+
+		switch {
+		case value.IsObject():
+			it := value.Range()
+			for it.Next() {
+				loc.length++
+				switch {
+				case loc.value.Exists():
+					if loc.nextStart == -1 {
+						loc.nextStart = it.Key().Index
+					}
+				case it.Key().Str == token.key:
+					loc.key, loc.value = it.Key(), it.Value()
+				default:
+					loc.prevEnd = it.Value().Index + len(it.Value().Raw)
+				}
+			}
+			if err, ok := it.Err().(*SyntaxError); ok {
+				return fail(MalformedJSON, err.Offset)
+			}
+		case value.IsArray():
+			index := token.index
+			if index == -1 && token.key != "-" {
+				return fail(InvalidIndex, value.Index)
+			}
+			it := value.Range()
+			for it.Next() {
+				switch {
+				case loc.length == index:
+					loc.value = it.Value()
+				case loc.value.Exists():
+					if loc.nextStart == -1 {
+						loc.nextStart = it.Value().Index
+					}
+				default:
+					loc.prevEnd = it.Value().Index + len(it.Value().Raw)
+				}
+				loc.length++
+			}
+			if err, ok := it.Err().(*SyntaxError); ok {
+				return fail(MalformedJSON, err.Offset)
+			}
+			if index > loc.length {
+				return fail(IndexOutOfRange, value.Index+len(value.Raw)-1)
+			}
+			loc.index = loc.length
+			if loc.value.Exists() {
+				loc.index = index
+			}
+		default:
+			return fail(NotContainer, value.Index)
+		}
+
+		if !loc.value.Exists() {
+			return loc, nil
+		}
+		value = loc.value
+	}
+
+	loc.found, loc.depth, loc.value = true, len(p.tokens), value
+	return loc, nil
+}
+
+// splice returns a copy of json with the bytes in [start, end) replaced by
+// the concatenation of insert.
+func splice[T Stringlike](json T, start, end int, insert ...[]byte) T {
+	n := len(json) - (end - start)
+	for _, b := range insert {
+		n += len(b)
+	}
+	buf := make([]byte, 0, n)
+	buf = append(buf, json[:start]...)
+	for _, b := range insert {
+		buf = append(buf, b...)
+	}
+	buf = append(buf, json[end:]...)
+	return T(buf)
+}
+
+// insertionPoint returns the offset at which a new member or element should be
+// appended to the given object or array, the separator that should precede
+// it, and the text that should separate a new member's key from its value.
+// The separator and colon mimic the formatting of the container's last
+// member so that pretty-printed documents remain pretty.
+func insertionPoint[T Stringlike](json T, container Result[T]) (offset int, sep, colon []byte) {
+	closing := container.Index + len(container.Raw) - 1
+	offset = closing
+	for offset > container.Index+1 && json[offset-1] <= ' ' {
+		offset--
+	}
+	if offset == container.Index+1 {
+		// the container is empty
+		return offset, nil, []byte{':'}
+	}
+
+	// find the last member or element
+	var last, lastKey Result[T]
+	for it := container.Range(); it.Next(); {
+		last, lastKey = it.Value(), it.Key()
+	}
+	start := last.Index
+	if container.IsObject() {
+		start = lastKey.Index
+		colon = []byte(json[lastKey.Index+len(lastKey.Raw) : last.Index])
+	}
+
+	// copy the whitespace that precedes the last member
+	ws := start
+	for ws > container.Index+1 && json[ws-1] <= ' ' {
+		ws--
+	}
+	sep = append([]byte{','}, json[ws:start]...)
+	return offset, sep, colon
+}
+
+// appendQuoted appends s to dst as a JSON string. Only the characters that
+// must be escaped are escaped, and invalid UTF-8 is replaced with U+FFFD.
+func appendQuoted(dst []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+
+	dst = append(dst, '"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			if r == utf8.RuneError && size == 1 {
+				dst = append(dst, "\ufffd"...)
+			} else {
+				dst = append(dst, s[i:i+size]...)
+			}
+			i += size
+			continue
+		}
+		switch c {
+		case '"', '\\':
+			dst = append(dst, '\\', c)
+		case '\b':
+			dst = append(dst, '\\', 'b')
+		case '\f':
+			dst = append(dst, '\\', 'f')
+		case '\n':
+			dst = append(dst, '\\', 'n')
+		case '\r':
+			dst = append(dst, '\\', 'r')
+		case '\t':
+			dst = append(dst, '\\', 't')
+		default:
+			if c < ' ' {
+				dst = append(dst, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			} else {
+				dst = append(dst, c)
+			}
+		}
+		i++
+	}
+	return append(dst, '"')
+}
+
+// wrapValue returns raw nested within objects keyed by the given reference
+// tokens, e.g. {"a":{"b":raw}} for the tokens "a" and "b".
+func wrapValue(tokens []referenceToken, raw []byte) []byte {
+	if len(tokens) == 0 {
+		return raw
+	}
+	var buf []byte
+	for _, t := range tokens {
+		buf = append(buf, '{')
+		buf = appendQuoted(buf, t.key)
+		buf = append(buf, ':')
+	}
+	buf = append(buf, raw...)
+	for range tokens {
+		buf = append(buf, '}')
+	}
+	return buf
+}
+
+// trimValue validates raw as a JSON value and trims any surrounding
+// whitespace.
+func trimValue(raw []byte) ([]byte, error) {
+	if err := Validate(raw); err != nil {
+		return nil, fmt.Errorf("invalid JSON value: %w", err)
+	}
+	value, _ := parseValueAt(raw, 0)
+	return value.Raw, nil
+}
+
+// Set returns a copy of json with the value referred to by the RFC 6901 JSON
+// pointer replaced by rawValue, which must be a valid JSON value. The pointer
+// is interpreted as it would be by GetStrict.
+//
+// If the value does not exist, it is inserted:
+//
+//   - if its parent is an object, a new member is appended to the object
+//   - if its parent is an array and the final reference token is "-" or the
+//     length of the array, a new element is appended to the array
+//   - any missing intermediate values are created as objects
+//
+// The rest of the document is left untouched, including its formatting.
+// If the pointer cannot refer to a value in the document, the returned error
+// is a *PointerError.
+func Set[T Stringlike](json T, pointer string, rawValue []byte) (T, error) {
+	raw, err := trimValue(rawValue)
+	if err != nil {
+		return json, err
+	}
+	p, err := ParsePointerStrict(pointer)
+	if err != nil {
+		return json, err
+	}
+
+	loc, perr := locate(json, p)
+	if perr != nil {
+		perr.Pointer = pointer
+		return json, perr
+	}
+	if loc.found {
+		return splice(json, loc.value.Index, loc.end(), raw), nil
+	}
+	return insert(json, &loc, p.tokens[loc.depth].key, wrapValue(p.tokens[loc.depth+1:], raw)), nil
+}
+
+// SetValue is like Set, but replaces the value with the JSON encoding of value
+// as produced by encoding/json.
+func SetValue[T Stringlike](json T, pointer string, value any) (T, error) {
+	raw, err := encjson.Marshal(value)
+	if err != nil {
+		return json, err
+	}
+	return Set(json, pointer, raw)
+}
+
+// insert returns a copy of json with raw appended to loc's parent. If the
+// parent is an object, the new member is given the specified key.
+func insert[T Stringlike](json T, loc *location[T], key string, raw []byte) T {
+	offset, sep, colon := insertionPoint(json, loc.parent)
+	if loc.parent.IsObject() {
+		return splice(json, offset, offset, sep, appendQuoted(nil, key), colon, raw)
+	}
+	return splice(json, offset, offset, sep, raw)
+}
+
+// notFound returns an error that describes the missing value at loc.
+func notFound[T Stringlike](loc *location[T], p Pointer) *PointerError {
+	kind := KeyNotFound
+	if loc.parent.IsArray() {
+		kind = IndexOutOfRange
+	}
+	return &PointerError{
+		Kind:       kind,
+		Token:      p.tokens[loc.depth].key,
+		TokenIndex: loc.depth,
+		Offset:     loc.parent.Index + len(loc.parent.Raw) - 1,
+	}
+}
+
+// add returns a copy of json with raw added at p as described by the RFC 6902
+// "add" operation: members of objects are replaced or inserted, and values
+// are inserted into arrays before the element at the given index.
+func add[T Stringlike](json T, p Pointer, raw []byte) (T, *PointerError) {
+	loc, err := locate(json, p)
+	if err != nil {
+		return json, err
+	}
+	switch {
+	case len(p.tokens) == 0:
+		return splice(json, loc.value.Index, loc.end(), raw), nil
+	case loc.found && loc.parent.IsArray():
+		// copy the whitespace that precedes the existing element
+		start := loc.value.Index
+		ws := start
+		for ws > loc.parent.Index+1 && json[ws-1] <= ' ' {
+			ws--
+		}
+		return splice(json, start, start, raw, []byte{','}, []byte(json[ws:start])), nil
+	case loc.found:
+		return splice(json, loc.value.Index, loc.end(), raw), nil
+	case loc.depth != len(p.tokens)-1:
+		return json, notFound(&loc, p)
+	default:
+		return insert(json, &loc, p.tokens[loc.depth].key, raw), nil
+	}
+}
+
+// remove returns a copy of json with the value at p removed, along with the
+// removed value.
+func remove[T Stringlike](json T, p Pointer) (T, Result[T], error) {
+	if len(p.tokens) == 0 {
+		return json, Result[T]{}, errors.New("cannot remove the whole document")
+	}
+	loc, err := locate(json, p)
+	if err != nil {
+		return json, Result[T]{}, err
+	}
+	if !loc.found {
+		return json, Result[T]{}, notFound(&loc, p)
+	}
+
+	// remove the member along with one of its adjacent commas
+	switch {
+	case loc.nextStart != -1:
+		json = splice(json, loc.start(), loc.nextStart)
+	case loc.prevEnd != -1:
+		json = splice(json, loc.prevEnd, loc.end())
+	default:
+		json = splice(json, loc.start(), loc.end())
+	}
+	return json, loc.value, nil
+}
+
+// Delete returns a copy of json with the object member or array element
+// referred to by the RFC 6901 JSON pointer removed. The pointer is interpreted
+// as it would be by GetStrict.
+//
+// The rest of the document is left untouched, including its formatting. If
+// the value does not exist, the returned error is a *PointerError.
+func Delete[T Stringlike](json T, pointer string) (T, error) {
+	p, err := ParsePointerStrict(pointer)
+	if err != nil {
+		return json, err
+	}
+	result, _, err := remove(json, p)
+	return result, withPointer(err, pointer)
+}
+
+// Move returns a copy of json with the value referred to by the pointer from
+// moved to the location referred to by the pointer to. As in an RFC 6902
+// "move" operation, the value is first removed and then added: if to refers
+// to an array element, the value is inserted before that element, and if to
+// refers to an existing object member, the member's value is replaced. The
+// pointers are interpreted as they would be by GetStrict.
+//
+// A value cannot be moved into one of its own children. If the value does
+// not exist or cannot be added at the new location, the returned error is a
+// *PointerError.
+func Move[T Stringlike](json T, from, to string) (T, error) {
+	fromPointer, err := ParsePointerStrict(from)
+	if err != nil {
+		return json, err
+	}
+	toPointer, err := ParsePointerStrict(to)
+	if err != nil {
+		return json, err
+	}
+	return move(json, fromPointer, toPointer, from, to)
+}
+
+func move[T Stringlike](json T, from, to Pointer, fromString, toString string) (T, error) {
+	if from.isPrefixOf(to) {
+		if len(from.tokens) == len(to.tokens) {
+			if _, err := locateExisting(json, from); err != nil {
+				return json, withPointer(err, fromString)
+			}
+			return json, nil
+		}
+		return json, errors.New("cannot move a value into one of its children")
+	}
+
+	result, value, err := remove(json, from)
+	if err != nil {
+		return json, withPointer(err, fromString)
+	}
+	// copy the raw value, as it may alias result's storage
+	raw := []byte(string(value.Raw))
+	result, perr := add(result, to, raw)
+	if perr != nil {
+		return json, withPointer(perr, toString)
+	}
+	return result, nil
+}
+
+// RenameKey returns a copy of json with the key of the object member referred
+// to by the RFC 6901 JSON pointer changed to newKey. The pointer is
+// interpreted as it would be by GetStrict.
+//
+// The member keeps its position within the object, and the rest of the
+// document is left untouched. An error is returned if the member does not
+// exist or if the object already has a member named newKey.
+func RenameKey[T Stringlike](json T, pointer, newKey string) (T, error) {
+	p, err := ParsePointerStrict(pointer)
+	if err != nil {
+		return json, err
+	}
+	loc, err := locateExisting(json, p)
+	if err != nil {
+		return json, withPointer(err, pointer)
+	}
+	if !loc.parent.IsObject() {
+		return json, errors.New("pointer does not refer to an object member")
+	}
+	if loc.key.Str == newKey {
+		return json, nil
+	}
+	for it := loc.parent.Range(); it.Next(); {
+		if it.Key().Str == newKey {
+			return json, errors.New("object already has a member named " + string(appendQuoted(nil, newKey)))
+		}
+	}
+	return splice(json, loc.key.Index, loc.key.Index+len(loc.key.Raw), appendQuoted(nil, newKey)), nil
+}
+
+// SortArray returns a copy of json with the elements of the array referred to
+// by the RFC 6901 JSON pointer sorted by the value that keyPointer refers to
+// within each element, in the order defined by Compare. Elements for which
+// keyPointer does not resolve sort first, and elements with equal keys keep
+// their relative order. An empty keyPointer sorts the elements by their own
+// values. Both pointers are interpreted as they would be by GetStrict.
+//
+// The elements keep their original formatting, as do the separators between
+// them and the rest of the document. If the value does not exist, the
+// returned error is a *PointerError.
+func SortArray[T Stringlike](json T, pointer, keyPointer string) (T, error) {
+	p, err := ParsePointerStrict(pointer)
+	if err != nil {
+		return json, err
+	}
+	kp, err := ParsePointerStrict(keyPointer)
+	if err != nil {
+		return json, err
+	}
+	loc, err := locateExisting(json, p)
+	if err != nil {
+		return json, withPointer(err, pointer)
+	}
+	if !loc.value.IsArray() {
+		return json, errors.New("pointer does not refer to an array")
+	}
+
+	type element struct {
+		value, key Result[T]
+	}
+	var elements []element
+	it := loc.value.Range()
+	for it.Next() {
+		value := it.Value()
+		elements = append(elements, element{value: value, key: getPointer(value.Raw, kp)})
+	}
+	if err := it.Err(); err != nil {
+		return json, err
+	}
+
+	sorted := make([]element, len(elements))
+	copy(sorted, elements)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return Compare(sorted[i].key, sorted[j].key) < 0
+	})
+
+	// write each sorted element into the slot of the original element at the
+	// same position
+	buf := make([]byte, 0, len(json))
+	last := 0
+	for i, slot := range elements {
+		buf = append(buf, json[last:slot.value.Index]...)
+		buf = append(buf, sorted[i].value.Raw...)
+		last = slot.value.Index + len(slot.value.Raw)
+	}
+	buf = append(buf, json[last:]...)
+	return T(buf), nil
+}
+
+// locateExisting is like locate, but returns an error if the value does not
+// exist.
+func locateExisting[T Stringlike](json T, p Pointer) (location[T], error) {
+	loc, err := locate(json, p)
+	if err != nil {
+		return loc, err
+	}
+	if !loc.found {
+		return loc, notFound(&loc, p)
+	}
+	return loc, nil
+}
+
+// withPointer sets the Pointer field of err if it is a *PointerError.
+func withPointer(err error, pointer string) error {
+	if perr, ok := err.(*PointerError); ok {
+		perr.Pointer = pointer
+		return perr
+	}
+	return err
+}
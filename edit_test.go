@@ -0,0 +1,279 @@
+package jp
+
+import (
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	cases := []struct {
+		json     string
+		pointer  string
+		value    string
+		expected string
+	}{
+		{`{"a":1,"b":2}`, "/a", `3`, `{"a":3,"b":2}`},
+		{`{"a":1,"b":2}`, "/b", ` "x" `, `{"a":1,"b":"x"}`},
+		{`{"a":1,"b":2}`, "/c", `true`, `{"a":1,"b":2,"c":true}`},
+		{`{}`, "/c", `true`, `{"c":true}`},
+		{`{ }`, "/c", `true`, `{"c":true }`},
+		{`{"a":1}`, "/b/c/d", `null`, `{"a":1,"b":{"c":{"d":null}}}`},
+		{`{"a":1}`, "/a~1b", `2`, `{"a":1,"a/b":2}`},
+		{`{"a":1}`, "/", `2`, `{"a":1,"":2}`},
+		{`{"a":[1,2]}`, "/a/0", `0`, `{"a":[0,2]}`},
+		{`{"a":[1,2]}`, "/a/-", `3`, `{"a":[1,2,3]}`},
+		{`{"a":[1,2]}`, "/a/2", `3`, `{"a":[1,2,3]}`},
+		{`{"a":[]}`, "/a/-", `3`, `{"a":[3]}`},
+		{`{"a":[]}`, "/a/-/b", `3`, `{"a":[{"b":3}]}`},
+		{`[{"a":1}]`, "/0/a", `{"b": 2}`, `[{"a":{"b": 2}}]`},
+		{` 1 `, "", `[2]`, ` [2] `},
+		{
+			"{\n  \"name\": \"Janet\",\n  \"age\": 47\n}",
+			"/email",
+			`"janet@example.com"`,
+			"{\n  \"name\": \"Janet\",\n  \"age\": 47,\n  \"email\": \"janet@example.com\"\n}",
+		},
+		{
+			"[\n\t1,\n\t2\n]",
+			"/-",
+			`3`,
+			"[\n\t1,\n\t2,\n\t3\n]",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.json+c.pointer, func(t *testing.T) {
+			actual, err := Set(c.json, c.pointer, []byte(c.value))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != c.expected {
+				t.Fatalf("expected %q, got %q", c.expected, actual)
+			}
+			actualBytes, err := Set([]byte(c.json), c.pointer, []byte(c.value))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(actualBytes) != c.expected {
+				t.Fatalf("expected %q, got %q", c.expected, actualBytes)
+			}
+		})
+	}
+}
+
+func TestSetErrors(t *testing.T) {
+	cases := []struct {
+		json    string
+		pointer string
+		kind    PointerErrorKind
+	}{
+		{`{"a":"b"}`, "/a/b", NotContainer},
+		{`{"a":[1]}`, "/a/2", IndexOutOfRange},
+		{`{"a":[1]}`, "/a/x", InvalidIndex},
+		{`{"a":[1]}`, "/a/01", InvalidIndex},
+		{``, "/a", MalformedJSON},
+		{`{"a":[1,2`, "/a/-", MalformedJSON},
+		{`{"a":1,"b`, "/c", MalformedJSON},
+	}
+	for _, c := range cases {
+		t.Run(c.json+c.pointer, func(t *testing.T) {
+			actual, err := Set(c.json, c.pointer, []byte("1"))
+			perr, ok := err.(*PointerError)
+			if !ok {
+				t.Fatalf("expected a *PointerError, got %v", err)
+			}
+			assert(t, perr.Kind == c.kind)
+			assert(t, perr.Pointer == c.pointer)
+			assert(t, actual == c.json)
+		})
+	}
+
+	_, err := Set(`{}`, "/a", []byte("{"))
+	assert(t, err != nil)
+	_, err = Set(`{}`, "a", []byte("1"))
+	_, ok := err.(*PointerSyntaxError)
+	assert(t, ok)
+}
+
+func TestSetValue(t *testing.T) {
+	actual, err := SetValue(`{"name":"Janet"}`, "/tags", []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, actual == `{"name":"Janet","tags":["a","b"]}`)
+
+	actual, err = SetValue(`{"name":"Janet"}`, "/name/first", "Janet")
+	assert(t, err != nil)
+	assert(t, actual == `{"name":"Janet"}`)
+}
+
+func TestAppendQuoted(t *testing.T) {
+	cases := map[string]string{
+		"":           `""`,
+		"hello":      `"hello"`,
+		"a\"b\\c":    `"a\"b\\c"`,
+		"\b\f\n\r\t": `"\b\f\n\r\t"`,
+		"\x00\x1f":   `"\u0000\u001f"`,
+		"<&>":        `"<&>"`,
+		"日本語":        `"日本語"`,
+		"\xff":       "\"�\"",
+	}
+	for s, expected := range cases {
+		if actual := string(appendQuoted(nil, s)); actual != expected {
+			t.Errorf("expected %s, got %s", expected, actual)
+		}
+	}
+}
+
+func TestDelete(t *testing.T) {
+	cases := []struct {
+		json     string
+		pointer  string
+		expected string
+	}{
+		{`{"a":1,"b":2,"c":3}`, "/a", `{"b":2,"c":3}`},
+		{`{"a":1,"b":2,"c":3}`, "/b", `{"a":1,"c":3}`},
+		{`{"a":1,"b":2,"c":3}`, "/c", `{"a":1,"b":2}`},
+		{`{"a":1}`, "/a", `{}`},
+		{`[1, 2, 3]`, "/0", `[2, 3]`},
+		{`[1, 2, 3]`, "/2", `[1, 2]`},
+		{`{"a":{"b":[true,{"c":null}]}}`, "/a/b/1/c", `{"a":{"b":[true,{}]}}`},
+		{"{\n  \"a\": 1,\n  \"b\": 2\n}", "/b", "{\n  \"a\": 1\n}"},
+		{"{\n  \"a\": 1,\n  \"b\": 2\n}", "/a", "{\n  \"b\": 2\n}"},
+	}
+	for _, c := range cases {
+		t.Run(c.json+c.pointer, func(t *testing.T) {
+			actual, err := Delete(c.json, c.pointer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != c.expected {
+				t.Fatalf("expected %q, got %q", c.expected, actual)
+			}
+		})
+	}
+
+	for _, pointer := range []string{"/x", "/a/0", "/b/3", "/b/-"} {
+		json := `{"a":1,"b":[1,2,3]}`
+		actual, err := Delete(json, pointer)
+		perr, ok := err.(*PointerError)
+		if !ok {
+			t.Fatalf("expected a *PointerError for %q, got %v", pointer, err)
+		}
+		assert(t, perr.Pointer == pointer)
+		assert(t, actual == json)
+	}
+
+	_, err := Delete(`{"a":1}`, "")
+	assert(t, err != nil)
+}
+
+func TestSortArray(t *testing.T) {
+	cases := []struct {
+		json       string
+		pointer    string
+		keyPointer string
+		expected   string
+	}{
+		{`[3,1,2]`, "", "", `[1,2,3]`},
+		{`{"a":[ "b", "a",  "c" ]}`, "/a", "", `{"a":[ "a", "b",  "c" ]}`},
+		{"[\n  3,\n  10,\n  2e0\n]", "", "", "[\n  2e0,\n  3,\n  10\n]"},
+		{`[{"n":"b","k":2},{"n":"a","k":1},{"n":"c","k":1}]`, "", "/k", `[{"n":"a","k":1},{"n":"c","k":1},{"n":"b","k":2}]`},
+		{`[{"k":"x"},{},{"k":null},{"k":[1]}]`, "", "/k", `[{},{"k":null},{"k":"x"},{"k":[1]}]`},
+		{`{"x":[[2,1],[1,2],[1]],"y":true}`, "/x", "/0", `{"x":[[1,2],[1],[2,1]],"y":true}`},
+		{`[]`, "", "", `[]`},
+	}
+	for _, c := range cases {
+		t.Run(c.json+c.pointer, func(t *testing.T) {
+			actual, err := SortArray(c.json, c.pointer, c.keyPointer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != c.expected {
+				t.Fatalf("expected %q, got %q", c.expected, actual)
+			}
+
+			actualBytes, err := SortArray([]byte(c.json), c.pointer, c.keyPointer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert(t, string(actualBytes) == c.expected)
+		})
+	}
+
+	json := `{"a":[1],"b":{}}`
+	_, err := SortArray(json, "/x", "")
+	perr, ok := err.(*PointerError)
+	if !ok {
+		t.Fatalf("expected a *PointerError, got %v", err)
+	}
+	assert(t, perr.Pointer == "/x")
+
+	actual, err := SortArray(json, "/b", "")
+	assert(t, err != nil)
+	assert(t, actual == json)
+
+	_, err = SortArray(json, "/a", "x")
+	assert(t, err != nil)
+}
+
+func TestMove(t *testing.T) {
+	cases := []struct {
+		json     string
+		from     string
+		to       string
+		expected string
+	}{
+		{`{"a":1,"b":2}`, "/a", "/c", `{"b":2,"c":1}`},
+		{`{"a":1,"b":2}`, "/a", "/b", `{"b":1}`},
+		{`{"a":{"x":[1,2]},"b":{}}`, "/a/x", "/b/y", `{"a":{},"b":{"y":[1,2]}}`},
+		{`[1,2,3,4]`, "/1", "/3", `[1,3,4,2]`},
+		{`[1,2,3,4]`, "/3", "/0", `[4,1,2,3]`},
+		{`{"a":[1,2],"b":3}`, "/b", "/a/-", `{"a":[1,2,3]}`},
+		{`{"a":1}`, "/a", "/a", `{"a":1}`},
+	}
+	for _, c := range cases {
+		t.Run(c.json+c.from+c.to, func(t *testing.T) {
+			actual, err := Move(c.json, c.from, c.to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != c.expected {
+				t.Fatalf("expected %q, got %q", c.expected, actual)
+			}
+		})
+	}
+
+	errorCases := []struct {
+		from string
+		to   string
+	}{
+		{"/x", "/y"},
+		{"/a", "/a/b"},
+		{"/a", "/x/y"},
+		{"/b", "/b"},
+	}
+	for _, c := range errorCases {
+		json := `{"a":{"b":1}}`
+		actual, err := Move(json, c.from, c.to)
+		assert(t, err != nil)
+		assert(t, actual == json)
+	}
+}
+
+func TestRenameKey(t *testing.T) {
+	actual, err := RenameKey(`{"a":1, "b":{"c":2}}`, "/b/c", "d/e")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, actual == `{"a":1, "b":{"d/e":2}}`)
+	assert(t, Get(actual, "/b/d~1e").Int() == 2)
+
+	actual, err = RenameKey(`{"a":1,"b":2}`, "/a", "a")
+	assert(t, err == nil && actual == `{"a":1,"b":2}`)
+
+	for _, c := range []struct{ pointer, key string }{{"/a", "b"}, {"/x", "y"}, {"/c/0", "y"}} {
+		json := `{"a":1,"b":2,"c":[1]}`
+		actual, err := RenameKey(json, c.pointer, c.key)
+		assert(t, err != nil)
+		assert(t, actual == json)
+	}
+}
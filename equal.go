@@ -0,0 +1,287 @@
+package jp
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Equal returns true if a and b are semantically equal JSON documents. See
+// Result.Equal for details.
+func Equal[T Stringlike](a, b T) bool {
+	return equal(Parse(a), Parse(b))
+}
+
+// Equal returns true if the result and other are semantically equal JSON
+// values:
+//
+//   - objects are equal if they have the same set of members, regardless of
+//     order
+//   - arrays are equal if they have the same length and their elements are
+//     equal
+//   - numbers are equal if they have the same value. Numbers are compared
+//     exactly, so large integers that are not representable as a float64
+//     are only equal if their digits are the same.
+//   - strings are equal if their unescaped contents are equal
+//
+// If an object has duplicate keys, the first member with a given key is used,
+// as it is by Get.
+func (t Result[T]) Equal(other Result[T]) bool {
+	return equal(t, other)
+}
+
+func equal[T Stringlike, U Stringlike](a Result[T], b Result[U]) bool {
+	switch {
+	case a.IsObject():
+		if !b.IsObject() {
+			return false
+		}
+		am, bm := a.Map(), b.Map()
+		if len(am) != len(bm) {
+			return false
+		}
+		for k, av := range am {
+			bv, ok := bm[k]
+			if !ok || !equal(av, bv) {
+				return false
+			}
+		}
+		return true
+	case a.IsArray():
+		if !b.IsArray() {
+			return false
+		}
+		ae, be := a.Array(), b.Array()
+		if len(ae) != len(be) {
+			return false
+		}
+		for i := range ae {
+			if !equal(ae[i], be[i]) {
+				return false
+			}
+		}
+		return true
+	case a.Type != b.Type:
+		return false
+	case a.Type == String:
+		return a.Str == b.Str
+	case a.Type == Number:
+		return compareNumbers(a, b) == 0
+	default:
+		return a.Exists() == b.Exists()
+	}
+}
+
+// Compare returns an integer comparing two JSON values: -1 if a sorts before
+// b, 0 if they are equal, and 1 if a sorts after b. Compare defines a total
+// order over JSON values that is consistent with Equal and that does not
+// depend on the formatting of the values:
+//
+//   - values of different types are ordered as they are by Result.Less, with
+//     arrays sorting before objects. A value that does not exist sorts
+//     before all others.
+//   - numbers are ordered by their exact values
+//   - strings are ordered by their unescaped contents, byte by byte
+//   - arrays are ordered lexicographically by their elements
+//   - objects are ordered lexicographically by their members sorted by key,
+//     comparing each member's key and then its value. As with Equal, if an
+//     object has duplicate keys, the first member with a given key is used.
+func Compare[T Stringlike](a, b Result[T]) int {
+	ra, rb := compareRank(a), compareRank(b)
+	switch {
+	case ra < rb:
+		return -1
+	case ra > rb:
+		return 1
+	}
+
+	switch {
+	case a.IsArray():
+		ae, be := a.Array(), b.Array()
+		for i := 0; i < len(ae) && i < len(be); i++ {
+			if c := Compare(ae[i], be[i]); c != 0 {
+				return c
+			}
+		}
+		return compareInts(len(ae), len(be))
+	case a.IsObject():
+		am, bm := a.Map(), b.Map()
+		ak, bk := sortedKeys(am), sortedKeys(bm)
+		for i := 0; i < len(ak) && i < len(bk); i++ {
+			if c := strings.Compare(ak[i], bk[i]); c != 0 {
+				return c
+			}
+			if c := Compare(am[ak[i]], bm[bk[i]]); c != 0 {
+				return c
+			}
+		}
+		return compareInts(len(ak), len(bk))
+	case a.Type == String:
+		return strings.Compare(a.Str, b.Str)
+	case a.Type == Number:
+		return compareNumbers(a, b)
+	case a.Type == JSON:
+		return strings.Compare(string(a.Raw), string(b.Raw))
+	default:
+		return 0
+	}
+}
+
+// compareRank returns the position of a value's type in the order defined by
+// Compare.
+func compareRank[T Stringlike](t Result[T]) int {
+	switch {
+	case !t.Exists():
+		return -1
+	case t.IsArray():
+		return int(JSON)
+	case t.IsObject():
+		return int(JSON) + 1
+	default:
+		return int(t.Type)
+	}
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// sortedKeys returns the keys of m in ascending order.
+func sortedKeys[T Stringlike](m map[string]Result[T]) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// compareNumbers compares the values of two numbers. Numbers that are valid
+// JSON are compared exactly; others are compared by their float64 values.
+func compareNumbers[T Stringlike, U Stringlike](a Result[T], b Result[U]) int {
+	ad, aok := parseDecimal(string(a.Raw))
+	bd, bok := parseDecimal(string(b.Raw))
+	if !aok || !bok {
+		switch {
+		case a.Num < b.Num:
+			return -1
+		case a.Num > b.Num:
+			return 1
+		default:
+			return 0
+		}
+	}
+	return ad.compare(bd)
+}
+
+// decimal is an exact representation of a JSON number. The value of the
+// number is 0.digits * 10^exp.
+type decimal struct {
+	neg bool
+	// digits holds the significant digits of the number without leading or
+	// trailing zeros. digits is empty if the number is zero.
+	digits string
+	exp    int
+}
+
+// parseDecimal parses a JSON number. It returns false if s is not a JSON
+// number or if its exponent is too large to represent.
+func parseDecimal(s string) (decimal, bool) {
+	var d decimal
+	if s != "" && s[0] == '-' {
+		d.neg, s = true, s[1:]
+	}
+
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	if i == 0 || (s[0] == '0' && i > 1) {
+		return decimal{}, false
+	}
+	intPart, s := s[:i], s[i:]
+
+	var fracPart string
+	if s != "" && s[0] == '.' {
+		i = 1
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+		if i == 1 {
+			return decimal{}, false
+		}
+		fracPart, s = s[1:i], s[i:]
+	}
+
+	exp := 0
+	if s != "" && (s[0] == 'e' || s[0] == 'E') {
+		e := s[1:]
+		if e != "" && e[0] == '+' {
+			e = e[1:]
+		}
+		if e == "" || e[0] == '-' && len(e) == 1 {
+			return decimal{}, false
+		}
+		for j := 0; j < len(e); j++ {
+			if (e[j] < '0' || e[j] > '9') && (j != 0 || e[j] != '-') {
+				return decimal{}, false
+			}
+		}
+		n, err := strconv.Atoi(e)
+		if err != nil || n > 1<<30 || n < -(1<<30) {
+			return decimal{}, false
+		}
+		exp, s = n, ""
+	}
+	if s != "" {
+		return decimal{}, false
+	}
+
+	digits := intPart + fracPart
+	exp += len(intPart)
+	trimmed := strings.TrimLeft(digits, "0")
+	exp -= len(digits) - len(trimmed)
+	d.digits = strings.TrimRight(trimmed, "0")
+	d.exp = exp
+	if d.digits == "" {
+		return decimal{}, true
+	}
+	return d, true
+}
+
+// compare returns -1, 0 or 1 if d is less than, equal to or greater than
+// other.
+func (d decimal) compare(other decimal) int {
+	if d.neg != other.neg {
+		if d.neg {
+			return -1
+		}
+		return 1
+	}
+	c := d.compareMagnitude(other)
+	if d.neg {
+		return -c
+	}
+	return c
+}
+
+func (d decimal) compareMagnitude(other decimal) int {
+	switch {
+	case d.digits == "" || other.digits == "":
+		return strings.Compare(d.digits, other.digits)
+	case d.exp != other.exp:
+		if d.exp < other.exp {
+			return -1
+		}
+		return 1
+	default:
+		return strings.Compare(d.digits, other.digits)
+	}
+}
@@ -0,0 +1,123 @@
+package jp
+
+import (
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected bool
+	}{
+		{`{"a":1,"b":2}`, `{ "b":2, "a":1.0 }`, true},
+		{`{"a":1,"b":2}`, `{"a":1}`, false},
+		{`{"a":1,"b":2}`, `{"a":1,"c":2}`, false},
+		{`{"a":1,"a":2}`, `{"a":1}`, true},
+		{`[1,2,3]`, `[ 1, 2, 3 ]`, true},
+		{`[1,2,3]`, `[1,3,2]`, false},
+		{`[1,2]`, `[1,2,3]`, false},
+		{`[]`, `{}`, false},
+		{`"abc"`, `"abc"`, true},
+		{`"\/"`, `"/"`, true},
+		{`"abc"`, `"abd"`, false},
+		{`1`, `"1"`, false},
+		{`true`, `true`, true},
+		{`true`, `false`, false},
+		{`null`, `null`, true},
+		{`null`, ``, false},
+		{`100`, `1e2`, true},
+		{`100`, `1E+2`, true},
+		{`0.001`, `1e-3`, true},
+		{`0`, `-0.0e5`, true},
+		{`1.5`, `15e-1`, true},
+		{`-1.5`, `1.5`, false},
+		{`9007199254740993`, `9007199254740992`, false},
+		{`12345678901234567890123`, `12345678901234567890123.000`, true},
+		{`12345678901234567890123`, `12345678901234567890124`, false},
+		{`{"a":[{"b":1e0}]}`, `{"a":[{"b":1}]}`, true},
+	}
+	for _, c := range cases {
+		t.Run(c.a+" "+c.b, func(t *testing.T) {
+			assert(t, Equal(c.a, c.b) == c.expected)
+			assert(t, Equal(c.b, c.a) == c.expected)
+			assert(t, Equal([]byte(c.a), []byte(c.b)) == c.expected)
+			assert(t, Parse(c.a).Equal(Parse(c.b)) == c.expected)
+		})
+	}
+}
+
+func TestCompareNumbers(t *testing.T) {
+	numbers := []string{"-1e400", "-12345678901234567890", "-1", "-0.5", "0", "1e-400", "0.5", "1", "2", "10", "9007199254740992", "9007199254740993", "1e400"}
+	for i, a := range numbers {
+		for j, b := range numbers {
+			expected := 0
+			if i < j {
+				expected = -1
+			} else if i > j {
+				expected = 1
+			}
+			actual := compareNumbers(Parse(a), Parse(b))
+			if actual != expected {
+				t.Fatalf("compareNumbers(%v, %v): expected %v, got %v", a, b, expected, actual)
+			}
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	// each value sorts strictly after the values that precede it
+	ordered := []string{
+		``,
+		`null`,
+		`false`,
+		`-1e400`,
+		`-1`,
+		`0`,
+		`0.5`,
+		`9007199254740992`,
+		`9007199254740993`,
+		`""`,
+		`"A"`,
+		`"a"`,
+		`"ab"`,
+		`"é"`,
+		`true`,
+		`[]`,
+		`[1]`,
+		`[1,2]`,
+		`[1,[]]`,
+		`[2]`,
+		`[true]`,
+		`{}`,
+		`{"a":1}`,
+		`{"a":1,"b":0}`,
+		`{"a":2}`,
+		`{"b":0}`,
+	}
+	for i, a := range ordered {
+		for j, b := range ordered {
+			expected := compareInts(i, j)
+			if actual := Compare(Parse(a), Parse(b)); actual != expected {
+				t.Fatalf("Compare(%s, %s): expected %d, got %d", a, b, expected, actual)
+			}
+		}
+	}
+
+	// formatting does not affect the order
+	equal := [][2]string{
+		{`[1, 2, {"a": [3]}]`, `[1,2,{"a":[3.0]}]`},
+		{`{"b":1,"a":[]}`, "{\n  \"a\": [ ],\n  \"b\": 1e0\n}"},
+		{`{"a":1,"a":2}`, `{"a":1}`},
+		{`"A"`, `"A"`},
+		{`100`, `1e2`},
+	}
+	for _, c := range equal {
+		a, b := Parse(c[0]), Parse(c[1])
+		if Compare(a, b) != 0 || Compare(b, a) != 0 {
+			t.Fatalf("expected %s and %s to be equal", c[0], c[1])
+		}
+		if !Equal(c[0], c[1]) {
+			t.Fatalf("expected %s and %s to be Equal", c[0], c[1])
+		}
+	}
+}
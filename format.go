@@ -0,0 +1,235 @@
+package jp
+
+import (
+	"sort"
+)
+
+// PrettyOptions controls the output of Pretty.
+type PrettyOptions struct {
+	// Width is the maximum width of a line that holds an entire array. Arrays
+	// that fit within Width when written on a single line are written on a
+	// single line; others are written with one element per line. If Width is
+	// zero, arrays are never written on a single line.
+	Width int
+	// Prefix is written at the beginning of each line.
+	Prefix string
+	// Indent is written once for each level of nesting.
+	Indent string
+	// SortKeys sorts the members of each object by key.
+	SortKeys bool
+}
+
+// DefaultPrettyOptions are the options used by Pretty when none are given.
+var DefaultPrettyOptions = &PrettyOptions{Width: 80, Prefix: "", Indent: "  ", SortKeys: false}
+
+// Pretty formats json for human consumption. Each member of an object is
+// written on its own line, as is each element of an array that does not fit
+// within the configured width. The output ends with a newline. If opts is
+// nil, DefaultPrettyOptions is used.
+//
+// Strings and numbers are copied exactly as they appear in json. Pretty
+// expects that json is well-formed; invalid json will not panic, but it may
+// produce unexpected results.
+func Pretty[T Stringlike](json T, opts *PrettyOptions) []byte {
+	if opts == nil {
+		opts = DefaultPrettyOptions
+	}
+	p := prettyPrinter[T]{json: json, opts: opts, buf: make([]byte, 0, len(json)+len(json)/2)}
+	i := p.skip(0)
+	if i == len(json) {
+		return p.buf
+	}
+	p.buf = append(p.buf, opts.Prefix...)
+	p.value(i, 0, false)
+	return append(p.buf, '\n')
+}
+
+// Compact removes insignificant whitespace from json. Strings and numbers are
+// copied exactly as they appear in json.
+func Compact[T Stringlike](json T) []byte {
+	buf := make([]byte, 0, len(json))
+	for i := 0; i < len(json); {
+		switch c := json[i]; c {
+		case ' ', '\t', '\n', '\r':
+			i++
+		case '"':
+			end, _, _, _ := parseString(json, i+1)
+			buf = append(buf, json[i:end]...)
+			i = end
+		default:
+			buf = append(buf, c)
+			i++
+		}
+	}
+	return buf
+}
+
+// prettyPrinter formats a JSON document.
+type prettyPrinter[T Stringlike] struct {
+	json T
+	opts *PrettyOptions
+	buf  []byte
+	// lineStart is the offset of the beginning of the current line in buf.
+	lineStart int
+}
+
+// prettyMember is a member of an object or an element of an array.
+type prettyMember struct {
+	// key is the unescaped key of an object member. It is only set if keys
+	// are sorted.
+	key string
+	// keyStart and keyEnd are the bounds of an object member's raw key.
+	keyStart, keyEnd int
+	// value is the offset of the value.
+	value int
+}
+
+// skip returns the offset of the first non-whitespace byte at or after i.
+func (p *prettyPrinter[T]) skip(i int) int {
+	for ; i < len(p.json) && p.json[i] <= ' '; i++ {
+	}
+	return i
+}
+
+// end returns the offset just past the value that begins at offset i.
+func (p *prettyPrinter[T]) end(i int) int {
+	var end int
+	switch p.json[i] {
+	case '{', '[':
+		end, _, _ = parseSquash(p.json, i)
+	case '"':
+		end, _, _, _ = parseString(p.json, i+1)
+	default:
+		end, _ = parseNumber(p.json, i)
+	}
+	return end
+}
+
+// overflows returns true if the current line is wider than the configured
+// width.
+func (p *prettyPrinter[T]) overflows() bool {
+	return len(p.buf)-p.lineStart > p.opts.Width
+}
+
+// newline begins a new line at the given depth.
+func (p *prettyPrinter[T]) newline(depth int) {
+	p.buf = append(p.buf, '\n')
+	p.lineStart = len(p.buf)
+	p.buf = append(p.buf, p.opts.Prefix...)
+	for ; depth > 0; depth-- {
+		p.buf = append(p.buf, p.opts.Indent...)
+	}
+}
+
+// value writes the value that begins at offset i and returns the offset just
+// past its end. If inline is true, the value is written on a single line,
+// and value returns false if the line overflows.
+func (p *prettyPrinter[T]) value(i, depth int, inline bool) (int, bool) {
+	switch p.json[i] {
+	case '{', '[':
+		return p.container(i, depth, inline)
+	default:
+		end := p.end(i)
+		p.buf = append(p.buf, p.json[i:end]...)
+		return end, true
+	}
+}
+
+// members returns the members or elements of the object or array that begins
+// at offset i and the offset just past its end.
+func (p *prettyPrinter[T]) members(i int) ([]prettyMember, int) {
+	json := p.json
+	obj, closing := json[i] == '{', json[i]+2
+
+	var members []prettyMember
+	for i++; ; {
+		for ; i < len(json) && (json[i] <= ' ' || json[i] == ','); i++ {
+		}
+		if i == len(json) {
+			break
+		}
+		if json[i] == closing {
+			i++
+			break
+		}
+
+		var m prettyMember
+		if obj {
+			if json[i] != '"' {
+				break
+			}
+			var raw T
+			var esc bool
+			m.keyStart = i
+			m.keyEnd, raw, esc, _ = parseString(json, i+1)
+			if p.opts.SortKeys {
+				if raw = raw[1 : len(raw)-1]; esc {
+					m.key = unescape(raw)
+				} else {
+					m.key = string(raw)
+				}
+			}
+			for i = m.keyEnd; i < len(json) && (json[i] <= ' ' || json[i] == ':'); i++ {
+			}
+			if i == len(json) {
+				break
+			}
+		}
+		m.value = i
+		members = append(members, m)
+		i = p.end(i)
+	}
+
+	if obj && p.opts.SortKeys {
+		sort.SliceStable(members, func(i, j int) bool {
+			return members[i].key < members[j].key
+		})
+	}
+	return members, i
+}
+
+// container writes the object or array that begins at offset i. Arrays are
+// written on a single line if they fit within the configured width.
+func (p *prettyPrinter[T]) container(i, depth int, inline bool) (int, bool) {
+	json := p.json
+	open, closing := json[i], json[i]+2
+	obj := open == '{'
+
+	if !inline && !obj && p.opts.Width > 0 {
+		mark, lineStart := len(p.buf), p.lineStart
+		if end, ok := p.container(i, depth, true); ok {
+			return end, true
+		}
+		p.buf, p.lineStart = p.buf[:mark], lineStart
+	}
+
+	members, end := p.members(i)
+	p.buf = append(p.buf, open)
+	if len(members) == 0 {
+		p.buf = append(p.buf, closing)
+		return end, !inline || !p.overflows()
+	}
+	for k, m := range members {
+		if k > 0 {
+			p.buf = append(p.buf, ',')
+			if inline {
+				p.buf = append(p.buf, ' ')
+			}
+		}
+		if !inline {
+			p.newline(depth + 1)
+		}
+		if obj {
+			p.buf = append(p.buf, json[m.keyStart:m.keyEnd]...)
+			p.buf = append(p.buf, ':', ' ')
+		}
+		if _, ok := p.value(m.value, depth+1, inline); !ok || inline && p.overflows() {
+			return end, false
+		}
+	}
+	if !inline {
+		p.newline(depth)
+	}
+	p.buf = append(p.buf, closing)
+	return end, !inline || !p.overflows()
+}
@@ -0,0 +1,107 @@
+package jp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPretty(t *testing.T) {
+	json := `{"name":{"first":"Tom","last":"Anderson"},"age":37,` +
+		`"children":["Sara","Alex","Jack"],"empty":{},"none":[ ],` +
+		`"friends":[{"first":"Dale","nets":["ig","fb","tw"]}],"s":"a\"b\\\u00e9","n":1.50e+3}`
+
+	expected := `{
+  "name": {
+    "first": "Tom",
+    "last": "Anderson"
+  },
+  "age": 37,
+  "children": ["Sara", "Alex", "Jack"],
+  "empty": {},
+  "none": [],
+  "friends": [{"first": "Dale", "nets": ["ig", "fb", "tw"]}],
+  "s": "a\"b\\\u00e9",
+  "n": 1.50e+3
+}
+`
+	if actual := string(Pretty(json, nil)); actual != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+	if actual := string(Pretty([]byte(json), nil)); actual != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+
+	expected = `// {
+// 	"age": 37,
+// 	"children": [
+// 		"Sara",
+// 		"Alex",
+// 		"Jack"
+// 	],
+// 	"empty": {},
+// 	"friends": [
+// 		{
+// 			"first": "Dale",
+// 			"nets": ["ig", "fb", "tw"]
+// 		}
+// 	],
+// 	"n": 1.50e+3,
+// 	"name": {
+// 		"first": "Tom",
+// 		"last": "Anderson"
+// 	},
+// 	"none": [],
+// 	"s": "a\"b\\\u00e9"
+// }
+`
+	opts := &PrettyOptions{Width: 32, Prefix: "// ", Indent: "\t", SortKeys: true}
+	if actual := string(Pretty(json, opts)); actual != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+
+	// without a width, arrays are never written on a single line
+	actual := string(Pretty(`[1,[2]]`, &PrettyOptions{Indent: " "}))
+	if actual != "[\n 1,\n [\n  2\n ]\n]\n" {
+		t.Fatalf("unexpected output %q", actual)
+	}
+
+	for _, json := range []string{"", "  \n", `"x"`, " 12 ", "null"} {
+		actual := string(Pretty(json, nil))
+		if expected := strings.TrimSpace(json); expected != "" {
+			expected += "\n"
+			if actual != expected {
+				t.Fatalf("expected %q, got %q", expected, actual)
+			}
+		} else if actual != "" {
+			t.Fatalf("expected empty output, got %q", actual)
+		}
+	}
+}
+
+func TestPrettySortKeysEscaped(t *testing.T) {
+	json := `{"b":1,"\u0061":2,"a\"":3}`
+	actual := string(Pretty(json, &PrettyOptions{Indent: "  ", SortKeys: true}))
+	expected := "{\n  \"\\u0061\": 2,\n  \"a\\\"\": 3,\n  \"b\": 1\n}\n"
+	if actual != expected {
+		t.Fatalf("expected %q, got %q", expected, actual)
+	}
+}
+
+func TestCompact(t *testing.T) {
+	tests := []struct{ json, expected string }{
+		{"", ""},
+		{" { \"a\" : [ 1 , 2.50 , true ] ,\n\t\"b\" : \"x y \\\" z\" } ", `{"a":[1,2.50,true],"b":"x y \" z"}`},
+		{"[\r\n]", "[]"},
+		{`"\u00e9 "`, `"\u00e9 "`},
+	}
+	for _, tt := range tests {
+		if actual := string(Compact(tt.json)); actual != tt.expected {
+			t.Fatalf("expected %q, got %q", tt.expected, actual)
+		}
+	}
+
+	json := `{"name":{"first":"Tom","last":"Anderson"},"children":["Sara","Alex"],"friends":[{"first":"Dale","nets":["ig"]}],"empty":[]}`
+	if actual := string(Compact(Pretty(json, nil))); actual != json {
+		t.Fatalf("round trip mismatch: %s", actual)
+	}
+}
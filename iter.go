@@ -0,0 +1,64 @@
+//go:build go1.23
+
+package jp
+
+import "iter"
+
+// All returns an iterator over the members of an object or the elements of an
+// array. For objects, the iterator yields the key and value of each member.
+// For arrays, it yields a Number result holding the index of each element and
+// the element itself. If the result is not an object or an array, the
+// iterator yields nothing.
+func (t Result[T]) All() iter.Seq2[Result[T], Result[T]] {
+	return func(yield func(Result[T], Result[T]) bool) {
+		for it := t.Range(); it.Next(); {
+			if !yield(it.Key(), it.Value()) {
+				return
+			}
+		}
+	}
+}
+
+// Keys returns an iterator over the unescaped keys of an object's members.
+// If the result is not an object, the iterator yields nothing.
+func (t Result[T]) Keys() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		if !t.IsObject() {
+			return
+		}
+		for it := t.Range(); it.Next(); {
+			if !yield(it.Key().Str) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns an iterator over the values of an object's members or the
+// elements of an array. If the result is not an object or an array, the
+// iterator yields nothing.
+func (t Result[T]) Values() iter.Seq[Result[T]] {
+	return func(yield func(Result[T]) bool) {
+		for it := t.Range(); it.Next(); {
+			if !yield(it.Value()) {
+				return
+			}
+		}
+	}
+}
+
+// Elements returns an iterator over the indices and values of an array's
+// elements. If the result is not an array, the iterator yields nothing.
+func (t Result[T]) Elements() iter.Seq2[int, Result[T]] {
+	return func(yield func(int, Result[T]) bool) {
+		if !t.IsArray() {
+			return
+		}
+		i := 0
+		for it := t.Range(); it.Next(); i++ {
+			if !yield(i, it.Value()) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+//go:build go1.23
+
+package jp
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestIterators(t *testing.T) {
+	json := `{"a":1,"b":[true,"x",null],"c\n":{"d":2}}`
+	root := Parse(json)
+
+	assert(t, slices.Equal(slices.Collect(root.Keys()), []string{"a", "b", "c\n"}))
+
+	var raws []string
+	for v := range root.Values() {
+		raws = append(raws, v.Raw)
+	}
+	assert(t, slices.Equal(raws, []string{`1`, `[true,"x",null]`, `{"d":2}`}))
+
+	members := map[string]string{}
+	for k, v := range root.All() {
+		members[k.Str] = v.Raw
+	}
+	assert(t, maps.Equal(members, map[string]string{"a": "1", "b": `[true,"x",null]`, "c\n": `{"d":2}`}))
+
+	b := root.Get("/b")
+	var indices []int
+	for i, v := range b.Elements() {
+		indices = append(indices, i)
+		assert(t, v.Raw == b.Array()[i].Raw)
+		assert(t, v.Index == Get(json, "/b/"+strconv.Itoa(i)).Index)
+	}
+	assert(t, slices.Equal(indices, []int{0, 1, 2}))
+
+	for k, v := range b.All() {
+		assert(t, b.Array()[k.Int()].Raw == v.Raw)
+	}
+
+	assert(t, len(slices.Collect(b.Keys())) == 0)
+	assert(t, len(slices.Collect(maps.Keys(maps.Collect(root.Elements())))) == 0)
+	assert(t, len(slices.Collect(Parse(`"x"`).Values())) == 0)
+
+	// stop early
+	n := 0
+	for range root.All() {
+		n++
+		break
+	}
+	assert(t, n == 1)
+}
@@ -2,6 +2,7 @@
 package jp
 
 import (
+	"fmt"
 	"reflect"
 	"strconv"
 	"strings"
@@ -224,11 +225,17 @@ func (t Result[T]) IsBool() bool {
 	return t.Type == True || t.Type == False
 }
 
+// Iterator iterates over the members of an object or the elements of an
+// array. Iterators are created by Result.Range.
 type Iterator[T Stringlike] struct {
 	root Result[T]
 
-	obj bool
-	i   int
+	obj  bool
+	i    int
+	done bool
+	err  error
+	// after is true once a member or element has been returned.
+	after bool
 
 	key   Result[T]
 	value Result[T]
@@ -258,51 +265,158 @@ func (it *Iterator[T]) init() bool {
 	return false
 }
 
+// fail stops the iteration and records a syntax error at offset i.
+func (it *Iterator[T]) fail(i int, msg string) bool {
+	it.done, it.err = true, &SyntaxError{Offset: it.root.Index + i, msg: msg}
+	return false
+}
+
+// Next advances the iterator to the next member or element. It returns false
+// at the end of the object or array, or if the object or array is malformed.
+// In the latter case, Err returns the error. A member or element is only
+// returned once it is known to be complete: it must be followed by a ',' or by
+// the end of the object or array.
 func (it *Iterator[T]) Next() bool {
-	if !it.init() {
+	if it.done || !it.init() {
 		return false
 	}
 
 	json := it.root.Raw
+	closing, context := byte(']'), "after array element"
+	if it.obj {
+		closing, context = '}', "after object value"
+	}
+
+	// find the start of the next member or element. The previous member or
+	// element, if any, was followed by a ',' or the closing bracket.
+	i := it.skip(it.i)
+	if i == len(json) {
+		return it.fail(i, "unexpected end of JSON input")
+	}
+	if json[i] == closing {
+		it.done = true
+		return false
+	}
+	if it.after {
+		if i = it.skip(i + 1); i == len(json) {
+			return it.fail(i, "unexpected end of JSON input")
+		}
+	}
 
 	var str T
 	var vesc bool
 	var ok bool
-	for ; it.i < len(json); it.i++ {
-		if it.obj {
-			if json[it.i] != '"' {
-				continue
-			}
-			s := it.i
-			it.i, str, vesc, ok = parseString(json, it.i+1)
-			if !ok {
-				return false
-			}
-			if vesc {
-				it.key.Str = unescape(str[1 : len(str)-1])
-			} else {
-				it.key.Str = string(str[1 : len(str)-1])
-			}
-			it.key.Raw = str
-			it.key.Index = s + it.root.Index
+	if it.obj {
+		if json[i] != '"' {
+			return it.fail(i, "invalid character "+quoteByte(json[i])+" looking for beginning of object key string")
+		}
+		s := i
+		i, str, vesc, ok = parseString(json, i+1)
+		if !ok {
+			return it.fail(s, "unterminated string")
+		}
+		if vesc {
+			it.key.Str = unescape(str[1 : len(str)-1])
 		} else {
-			it.key.Num += 1
+			it.key.Str = string(str[1 : len(str)-1])
 		}
-		for ; it.i < len(json); it.i++ {
-			if json[it.i] <= ' ' || json[it.i] == ',' || json[it.i] == ':' {
-				continue
+		it.key.Raw = str
+		it.key.Index = s + it.root.Index
+
+		if i = it.skip(i); i == len(json) {
+			return it.fail(i, "unexpected end of JSON input")
+		}
+		if json[i] != ':' {
+			return it.fail(i, "expected ':' after object key")
+		}
+		if i = it.skip(i + 1); i == len(json) {
+			return it.fail(i, "unexpected end of JSON input")
+		}
+	} else {
+		it.key.Num += 1
+	}
+
+	s := i
+	switch json[i] {
+	case '{', '[', '"', 't', 'f', 'n', '+', '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9', 'i', 'I', 'N':
+	default:
+		return it.fail(i, "invalid character "+quoteByte(json[i])+" looking for beginning of value")
+	}
+	i, it.value, ok = parseAny(json, i, true)
+	if !ok {
+		if json[s] == '"' {
+			return it.fail(s, "unterminated string")
+		}
+		return it.fail(i, "unexpected end of JSON input")
+	}
+	switch raw := it.value.Raw; {
+	case it.value.Type == JSON:
+		switch last := raw[len(raw)-1]; {
+		case last != '}' && last != ']':
+			return it.fail(i, "unexpected end of JSON input")
+		case last != raw[0]+2:
+			if raw[0] == '{' {
+				return it.fail(i-1, "invalid character "+quoteByte(last)+" after object value")
 			}
-			break
+			return it.fail(i-1, "invalid character "+quoteByte(last)+" after array element")
 		}
-		s := it.i
-		it.i, it.value, ok = parseAny(json, it.i, true)
-		if !ok {
-			return false
+	case json[s] == 't' || json[s] == 'f' || json[s] == 'n' && it.value.Type == Null:
+		lit := "null"
+		if json[s] == 't' {
+			lit = "true"
+		} else if json[s] == 'f' {
+			lit = "false"
 		}
-		it.value.Index = s + it.root.Index
-		return true
+		v := validator[T]{data: json}
+		if i, ok = v.literal(s, lit); !ok {
+			return it.fail(v.offset, v.reason)
+		}
+		it.value.Raw = json[s:i]
 	}
-	return false
+
+	// the member or element must be followed by a ',' or the closing bracket
+	if i = it.skip(i); i == len(json) {
+		return it.fail(i, "unexpected end of JSON input")
+	}
+	if json[i] != ',' && json[i] != closing {
+		return it.fail(i, "invalid character "+quoteByte(json[i])+" "+context)
+	}
+	it.i, it.after = i, true
+	it.value.Index = s + it.root.Index
+	return true
+}
+
+// skip returns the offset of the first non-whitespace byte at or after i.
+func (it *Iterator[T]) skip(i int) int {
+	json := it.root.Raw
+	for ; i < len(json) && json[i] <= ' '; i++ {
+	}
+	return i
+}
+
+// Err returns the error, if any, that stopped the iteration. The error is a
+// *SyntaxError that describes where the object or array is malformed or
+// truncated.
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// SyntaxError describes malformed JSON.
+type SyntaxError struct {
+	// Offset is the byte offset of the error within the document.
+	Offset int
+	// Line and Column are the 1-based line and byte column of the error, or
+	// zero if they are unknown.
+	Line, Column int
+
+	msg string
+}
+
+func (e *SyntaxError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("invalid JSON at offset %d: %s", e.Offset, e.msg)
+	}
+	return fmt.Sprintf("invalid JSON at line %d, column %d (offset %d): %s", e.Line, e.Column, e.Offset, e.msg)
 }
 
 func (it *Iterator[T]) Key() Result[T] {
@@ -331,6 +445,19 @@ func (t Result[T]) ForEach(iterator func(key, value Result[T]) bool) {
 	}
 }
 
+// ForEachErr is like ForEach, but returns an error if the object or array is
+// malformed or truncated. Values that precede the error are still passed to
+// the iterator. See Iterator.Err for details.
+func (t Result[T]) ForEachErr(iterator func(key, value Result[T]) bool) error {
+	it := t.Range()
+	for it.Next() {
+		if !iterator(it.Key(), it.Value()) {
+			return nil
+		}
+	}
+	return it.Err()
+}
+
 // Map returns back a map of values. The result should be a JSON object.
 // If the result is not a JSON object, the return value will be an empty map.
 func (t Result[T]) Map() map[string]Result[T] {
@@ -349,6 +476,14 @@ func (t Result[T]) Get(path string) Result[T] {
 	return r
 }
 
+// GetPointer searches result for the specified precompiled pointer.
+// The result should be a JSON array or object.
+func (t Result[T]) GetPointer(p Pointer) Result[T] {
+	r := getPointer(t.Raw, p)
+	r.Index += t.Index
+	return r
+}
+
 type arrayOrMapResult[T Stringlike] struct {
 	a  []Result[T]
 	ai []interface{}
@@ -479,7 +614,7 @@ func Parse[T Stringlike](json T) Result[T] {
 	for ; i < len(json); i++ {
 		if json[i] == '{' || json[i] == '[' {
 			value.Type = JSON
-			value.Raw = json[i:] // just take the entire raw
+			value.Raw, value.len = squash(json[i:])
 			break
 		}
 		if json[i] <= ' ' {
@@ -520,6 +655,40 @@ func Parse[T Stringlike](json T) Result[T] {
 	return value
 }
 
+// NextValue parses the value that begins at or after offset i in json, which
+// may contain several concatenated top-level values such as
+// `{"a":1}{"b":2}` or `1 2 3`. It returns the value, whose Index is its
+// offset within json, and the offset just past its end, from which the next
+// value can be parsed.
+//
+// If only whitespace remains at or after offset i, or if no value begins
+// there, the returned value does not exist and the returned offset is
+// unchanged.
+func NextValue[T Stringlike](json T, i int) (Result[T], int) {
+	if i >= len(json) {
+		return Result[T]{}, i
+	}
+	value := Parse(json[i:])
+	if !value.Exists() {
+		return Result[T]{}, i
+	}
+	value.Index += i
+	return value, value.Index + len(value.Raw)
+}
+
+// ParseAll parses each of the concatenated top-level values in json. Parsing
+// stops at the end of json or at the first byte that does not begin a value.
+func ParseAll[T Stringlike](json T) []Result[T] {
+	var values []Result[T]
+	for i := 0; ; {
+		var value Result[T]
+		if value, i = NextValue(json, i); !value.Exists() {
+			return values
+		}
+		values = append(values, value)
+	}
+}
+
 func squash[T Stringlike](json T) (T, int) {
 	// expects that the lead character is a '[' or '{' or '(' or '"'
 	// squash the value, ignoring all nested arrays and objects.
@@ -529,6 +698,10 @@ func squash[T Stringlike](json T) (T, int) {
 		i, depth = 1, 1
 	}
 	for ; i < len(json); i++ {
+		if !any && json[i] > ' ' && json[i] != '}' && json[i] != ']' && json[i] != ')' {
+			// any value, including a lone number or literal, is an element
+			any = true
+		}
 		if json[i] >= '"' && json[i] <= '}' {
 			switch json[i] {
 			case '"':
@@ -769,74 +942,6 @@ func parseLiteral[T Stringlike](json T, i int) (int, T) {
 	return i, json[s:]
 }
 
-func getReferenceToken(pointer string) (token, rest string) {
-	// find the end of the pointer or the next '/'
-	sep, escaped := -1, false
-	for i := 0; i < len(pointer); i++ {
-		c := pointer[i]
-		if c == '/' {
-			sep = i
-			break
-		} else if c == '~' {
-			escaped = true
-		}
-	}
-
-	ref := ""
-	if sep == -1 {
-		ref, rest = pointer, ""
-	} else {
-		ref = pointer[:sep]
-		for sep < len(pointer) && pointer[sep] == '/' {
-			sep++
-		}
-		rest = pointer[sep:]
-	}
-
-	if escaped {
-		var b strings.Builder
-		b.Grow(len(ref))
-		for i := 0; i < len(ref); i++ {
-			c := ref[i]
-			if c == '~' {
-				i++
-				if i == len(ref) {
-					b.WriteByte(c)
-					break
-				}
-
-				c = ref[i]
-				if c == '0' {
-					c = '~'
-				} else if c == '1' {
-					c = '/'
-				}
-			}
-			b.WriteByte(c)
-		}
-		ref = b.String()
-	}
-
-	return ref, rest
-}
-
-func getArrayIndex(pointer string) (index int, rest string) {
-	ref, rest := getReferenceToken(pointer)
-	if ref == "" {
-		return -1, ""
-	}
-
-	index = 0
-	for i := 0; i < len(ref); i++ {
-		c := ref[i]
-		if c < '0' || c > '9' {
-			return -1, ""
-		}
-		index = index*10 + int(c) - '0'
-	}
-	return index, rest
-}
-
 func parseSquash[T Stringlike](json T, i int) (int, T, int) {
 	// expects that the lead character is a '[' or '{' or '('
 	// squash the value, ignoring all nested arrays and objects.
@@ -845,6 +950,10 @@ func parseSquash[T Stringlike](json T, i int) (int, T, int) {
 	i++
 	depth, count, any := 1, 0, false
 	for ; i < len(json); i++ {
+		if !any && json[i] > ' ' && json[i] != '}' && json[i] != ']' && json[i] != ')' {
+			// any value, including a lone number or literal, is an element
+			any = true
+		}
 		if json[i] >= '"' && json[i] <= '}' {
 			switch json[i] {
 			case '"':
@@ -895,21 +1004,14 @@ func parseSquash[T Stringlike](json T, i int) (int, T, int) {
 	return i, json[s:], count
 }
 
-func parseObject[T Stringlike](c *parseContext[T], i int, pointer string) (int, bool) {
+func parseObject[T Stringlike](c *parseContext[T], i int, pointer []referenceToken) (int, bool) {
 	var pmatch, kesc, vesc, ok, hit bool
-	var ref string
 	var key, val T
 	var count int
-	ref, pointer = getReferenceToken(pointer)
-	if ref == "" {
-		// return the entire object
-		i, val, count = parseSquash(c.json, i-1)
-		c.value.Raw = val
-		c.value.Type = JSON
-		c.value.len = count
-		return i, true
-	}
-	more := pointer != ""
+	depth := len(c.pointer) - len(pointer)
+	ref := pointer[0].key
+	pointer = pointer[1:]
+	more := len(pointer) != 0
 
 	for i < len(c.json) {
 		for ; i < len(c.json); i++ {
@@ -959,10 +1061,12 @@ func parseObject[T Stringlike](c *parseContext[T], i int, pointer string) (int,
 				break
 			}
 			if c.json[i] == '}' {
+				c.fail(KeyNotFound, depth, i, Null)
 				return i + 1, false
 			}
 		}
 		if !ok {
+			c.fail(MalformedJSON, depth, i, Null)
 			return i, false
 		}
 		if kesc {
@@ -978,11 +1082,16 @@ func parseObject[T Stringlike](c *parseContext[T], i int, pointer string) (int,
 			default:
 				continue
 			case '"':
+				s := i
 				i++
 				i, val, vesc, ok = parseString(c.json, i)
 				if !ok {
+					c.fail(MalformedJSON, depth, i, Null)
 					return i, false
 				}
+				if pmatch && !hit {
+					c.fail(NotContainer, depth+1, s, String)
+				}
 				if hit {
 					if vesc {
 						c.value.Str = unescape(val[1 : len(val)-1])
@@ -1030,8 +1139,11 @@ func parseObject[T Stringlike](c *parseContext[T], i int, pointer string) (int,
 				}
 				fallthrough
 			case 't', 'f':
-				vc := c.json[i]
+				vc, s := c.json[i], i
 				i, val = parseLiteral(c.json, i)
+				if pmatch && !hit {
+					c.fail(NotContainer, depth+1, s, literalType(vc))
+				}
 				if hit {
 					c.value.Raw = val
 					switch vc {
@@ -1047,7 +1159,11 @@ func parseObject[T Stringlike](c *parseContext[T], i int, pointer string) (int,
 				num = true
 			}
 			if num {
+				s := i
 				i, val = parseNumber(c.json, i)
+				if pmatch && !hit {
+					c.fail(NotContainer, depth+1, s, Number)
+				}
 				if hit {
 					c.value.Raw = val
 					c.value.Type = Number
@@ -1058,17 +1174,28 @@ func parseObject[T Stringlike](c *parseContext[T], i int, pointer string) (int,
 			break
 		}
 	}
+	c.fail(MalformedJSON, depth, i, Null)
 	return i, false
 }
 
-func parseArray[T Stringlike](c *parseContext[T], i int, pointer string) (int, bool) {
+func parseArray[T Stringlike](c *parseContext[T], i int, pointer []referenceToken) (int, bool) {
 	var pmatch, vesc, ok, hit bool
 	var val T
 	var h int
-	var partidx int
 	var count int
-	partidx, pointer = getArrayIndex(pointer)
+	depth := len(c.pointer) - len(pointer)
+	partidx := pointer[0].index
 	if partidx == -1 {
+		if c.strict || c.errors {
+			// the token does not refer to an element
+			if pointer[0].key == "-" {
+				c.fail(IndexOutOfRange, depth, i-1, Null)
+			} else {
+				c.fail(InvalidIndex, depth, i-1, Null)
+			}
+			i, _, _ = parseSquash(c.json, i-1)
+			return i, false
+		}
 		// return the entire object
 		i, val, count = parseSquash(c.json, i-1)
 		c.value.Raw = val
@@ -1076,7 +1203,8 @@ func parseArray[T Stringlike](c *parseContext[T], i int, pointer string) (int, b
 		c.value.len = count
 		return i, true
 	}
-	more := pointer != ""
+	pointer = pointer[1:]
+	more := len(pointer) != 0
 
 	for i < len(c.json)+1 {
 		pmatch = partidx == h
@@ -1097,11 +1225,16 @@ func parseArray[T Stringlike](c *parseContext[T], i int, pointer string) (int, b
 			default:
 				continue
 			case '"':
+				s := i
 				i++
 				i, val, vesc, ok = parseString(c.json, i)
 				if !ok {
+					c.fail(MalformedJSON, depth, i, Null)
 					return i, false
 				}
+				if pmatch && !hit {
+					c.fail(NotContainer, depth+1, s, String)
+				}
 				if hit {
 					if vesc {
 						c.value.Str = unescape(val[1 : len(val)-1])
@@ -1149,8 +1282,11 @@ func parseArray[T Stringlike](c *parseContext[T], i int, pointer string) (int, b
 				}
 				fallthrough
 			case 't', 'f':
-				vc := c.json[i]
+				vc, s := c.json[i], i
 				i, val = parseLiteral(c.json, i)
+				if pmatch && !hit {
+					c.fail(NotContainer, depth+1, s, literalType(vc))
+				}
 				if hit {
 					c.value.Raw = val
 					switch vc {
@@ -1165,10 +1301,19 @@ func parseArray[T Stringlike](c *parseContext[T], i int, pointer string) (int, b
 				'i', 'I', 'N':
 				num = true
 			case ']':
+				if i == len(c.json) {
+					c.fail(MalformedJSON, depth, i, Null)
+				} else {
+					c.fail(IndexOutOfRange, depth, i, Null)
+				}
 				return i + 1, false
 			}
 			if num {
+				s := i
 				i, val = parseNumber(c.json, i)
+				if pmatch && !hit {
+					c.fail(NotContainer, depth+1, s, Number)
+				}
 				if hit {
 					c.value.Raw = val
 					c.value.Type = Number
@@ -1179,12 +1324,46 @@ func parseArray[T Stringlike](c *parseContext[T], i int, pointer string) (int, b
 			break
 		}
 	}
+	c.fail(MalformedJSON, depth, i, Null)
 	return i, false
 }
 
 type parseContext[T Stringlike] struct {
-	json  T
-	value Result[T]
+	json   T
+	value  Result[T]
+	strict bool
+
+	// pointer is the full pointer being resolved.
+	pointer []referenceToken
+	// errors is true if the reason for a failed lookup should be recorded in
+	// err.
+	errors bool
+	err    *PointerError
+}
+
+// fail records the reason that the reference token at the given depth could
+// not be resolved. Only the first failure is recorded, as it is the one that
+// prevented the pointer from resolving.
+func (c *parseContext[T]) fail(kind PointerErrorKind, depth, offset int, typ Type) {
+	if !c.errors || c.err != nil {
+		return
+	}
+	c.err = &PointerError{Kind: kind, TokenIndex: depth, Offset: offset, Type: typ}
+	if depth < len(c.pointer) {
+		c.err.Token = c.pointer[depth].key
+	}
+}
+
+// literalType returns the type of the literal that begins with c.
+func literalType(c byte) Type {
+	switch c {
+	case 't':
+		return True
+	case 'f':
+		return False
+	default:
+		return Null
+	}
 }
 
 // Get searches json for the specified RFC 6901 JSON pointer.
@@ -1194,29 +1373,99 @@ type parseContext[T Stringlike] struct {
 // If you are consuming JSON from an unpredictable source then you may want to
 // use the Valid function first.
 func Get[T Stringlike](json T, pointer string) Result[T] {
-	for len(pointer) > 0 && pointer[0] == '/' {
-		pointer = pointer[1:]
+	var buf [8]referenceToken
+	tokens, _ := appendReferenceTokens(buf[:0], pointer)
+	return getPointer(json, Pointer{tokens: tokens})
+}
+
+// GetStrict searches json for the specified RFC 6901 JSON pointer, following
+// the RFC exactly. See ParsePointerStrict for the differences between this
+// function and Get. An error is returned if the pointer is malformed.
+//
+// This function expects that the json is well-formed, and does not validate.
+// Invalid json will not panic, but it may return back unexpected results.
+// If you are consuming JSON from an unpredictable source then you may want to
+// use the Valid function first.
+func GetStrict[T Stringlike](json T, pointer string) (Result[T], error) {
+	p, err := ParsePointerStrict(pointer)
+	if err != nil {
+		return Result[T]{}, err
 	}
-	if pointer == "" {
-		_, result, _ := parseAny(json, 0, true)
-		return result
+	return getPointer(json, p), nil
+}
+
+// Lookup searches json for the specified RFC 6901 JSON pointer. The pointer
+// is interpreted as it would be by Get. If the pointer does not resolve, the
+// returned error is a *PointerError that describes why.
+//
+// Unlike Get, Lookup does not treat a reference token that is not an array
+// index as referring to the whole array; such tokens are reported as
+// InvalidIndex errors.
+//
+// This function expects that the json is well-formed, and does not validate.
+// Malformed json is reported when it prevents the pointer from resolving, but
+// it may otherwise return back unexpected results.
+func Lookup[T Stringlike](json T, pointer string) (Result[T], error) {
+	var buf [8]referenceToken
+	tokens, _ := appendReferenceTokens(buf[:0], pointer)
+	result, err := lookupPointer(json, Pointer{tokens: tokens})
+	if err != nil {
+		err.Pointer = pointer
+		return result, err
+	}
+	return result, nil
+}
+
+func getPointer[T Stringlike](json T, p Pointer) Result[T] {
+	c := parseContext[T]{json: json, strict: p.strict}
+	resolvePointer(&c, p.tokens)
+	return c.value
+}
+
+func lookupPointer[T Stringlike](json T, p Pointer) (Result[T], *PointerError) {
+	c := parseContext[T]{json: json, strict: p.strict, pointer: p.tokens, errors: true}
+	resolvePointer(&c, p.tokens)
+	if c.value.Exists() {
+		return c.value, nil
+	}
+	if c.err == nil {
+		c.fail(MalformedJSON, 0, len(json), Null)
+	}
+	return Result[T]{}, c.err
+}
+
+func resolvePointer[T Stringlike](c *parseContext[T], pointer []referenceToken) {
+	if len(pointer) == 0 {
+		i, result, ok := parseAny(c.json, 0, true)
+		if !ok {
+			c.fail(MalformedJSON, 0, i, Null)
+		}
+		c.value = result
+		return
 	}
 
-	c := parseContext[T]{json: json}
 	for i := 0; i < len(c.json); i++ {
 		if c.json[i] == '{' {
 			i++
-			parseObject(&c, i, pointer)
+			parseObject(c, i, pointer)
 			break
 		}
 		if c.json[i] == '[' {
 			i++
-			parseArray(&c, i, pointer)
+			parseArray(c, i, pointer)
 			break
 		}
 	}
-	fillIndex(json, &c)
-	return c.value
+	if c.errors && !c.value.Exists() {
+		// report a document that is not a container
+		i, v, ok := parseAny(c.json, 0, true)
+		if !ok {
+			c.fail(MalformedJSON, 0, i, Null)
+		} else if v.Type != JSON {
+			c.fail(NotContainer, 0, i-len(v.Raw), v.Type)
+		}
+	}
+	fillIndex(c.json, c)
 }
 
 // runeit returns the rune from the the \uXXXX
@@ -1294,6 +1543,8 @@ func unescape[T Stringlike](json T) string {
 //
 //  Null < False < Number < String < True < JSON
 //
+// JSON values are compared by their raw text. Use Compare for an order that
+// does not depend on formatting.
 func (t Result[T]) Less(token Result[T], caseSensitive bool) bool {
 	if t.Type < token.Type {
 		return true
@@ -1430,29 +1681,96 @@ func parseAny[T Stringlike](json T, i int, hit bool) (int, Result[T], bool) {
 	return i, res, false
 }
 
+// getManyPassThreshold is the number of paths up to which GetMany resolves
+// each path separately. For few paths, building a PointerSet costs more than
+// the extra passes over json.
+const getManyPassThreshold = 8
+
 // GetMany searches json for the multiple paths.
 // The return value is a Result array where the number of items
 // will be equal to the number of input paths.
+//
+// If there are more than a few paths, they are resolved in a single pass over
+// json. If the same paths are used repeatedly, consider using a PointerSet
+// instead.
 func GetMany[T Stringlike](json T, path ...string) []Result[T] {
-	res := make([]Result[T], len(path))
-	for i, path := range path {
-		res[i] = Get(json, path)
+	if len(path) <= getManyPassThreshold {
+		res := make([]Result[T], len(path))
+		for i, path := range path {
+			res[i] = Get(json, path)
+		}
+		return res
 	}
-	return res
+
+	var s PointerSet
+	for _, path := range path {
+		tokens, _ := appendReferenceTokens(nil, path)
+		s.add(Pointer{tokens: tokens})
+	}
+	return getMany(json, &s)
+}
+
+// validator checks that a document is valid JSON and records the position
+// and reason of the first error.
+type validator[T Stringlike] struct {
+	data   T
+	limits ValidateOptions
+	depth  int
+
+	offset int
+	reason string
+	// limit is the limit that was exceeded, if any.
+	limit Limit
+}
+
+// fail records an error at offset i and returns (i, false).
+func (v *validator[T]) fail(i int, reason string) (int, bool) {
+	v.offset, v.reason = i, reason
+	return i, false
+}
+
+// exceed records that limit was exceeded at offset i.
+func (v *validator[T]) exceed(i int, limit Limit) (int, bool) {
+	v.offset, v.limit = i, limit
+	return i, false
+}
+
+// eof records an unexpected end of input.
+func (v *validator[T]) eof() (int, bool) {
+	return v.fail(len(v.data), "unexpected end of JSON input")
+}
+
+// unexpected records an unexpected character at offset i.
+func (v *validator[T]) unexpected(i int, context string) (int, bool) {
+	return v.fail(i, "invalid character "+quoteByte(v.data[i])+" "+context)
+}
+
+// quoteByte returns a description of c suitable for an error message.
+func quoteByte(c byte) string {
+	if c < utf8.RuneSelf {
+		return strconv.QuoteRune(rune(c))
+	}
+	return fmt.Sprintf("'\\x%02x'", c)
 }
 
 func validpayload[T Stringlike](data T, i int) (outi int, ok bool) {
+	v := validator[T]{data: data}
+	return v.payload(i)
+}
+
+func (v *validator[T]) payload(i int) (outi int, ok bool) {
+	data := v.data
 	for ; i < len(data); i++ {
 		switch data[i] {
 		default:
-			i, ok = validany(data, i)
+			i, ok = v.any(i)
 			if !ok {
 				return i, false
 			}
 			for ; i < len(data); i++ {
 				switch data[i] {
 				default:
-					return i, false
+					return v.unexpected(i, "after top-level value")
 				case ' ', '\t', '\n', '\r':
 					continue
 				}
@@ -1462,54 +1780,72 @@ func validpayload[T Stringlike](data T, i int) (outi int, ok bool) {
 			continue
 		}
 	}
-	return i, false
+	return v.eof()
 }
-func validany[T Stringlike](data T, i int) (outi int, ok bool) {
+
+func (v *validator[T]) any(i int) (outi int, ok bool) {
+	data := v.data
 	for ; i < len(data); i++ {
 		switch data[i] {
 		default:
-			return i, false
+			return v.unexpected(i, "looking for beginning of value")
 		case ' ', '\t', '\n', '\r':
 			continue
 		case '{':
-			return validobject(data, i+1)
+			if v.depth++; v.limits.MaxDepth > 0 && v.depth > v.limits.MaxDepth {
+				return v.exceed(i, DepthLimit)
+			}
+			i, ok = v.object(i + 1)
+			v.depth--
+			return i, ok
 		case '[':
-			return validarray(data, i+1)
+			if v.depth++; v.limits.MaxDepth > 0 && v.depth > v.limits.MaxDepth {
+				return v.exceed(i, DepthLimit)
+			}
+			i, ok = v.array(i + 1)
+			v.depth--
+			return i, ok
 		case '"':
-			return validstring(data, i+1)
+			return v.string(i + 1)
 		case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
-			return validnumber(data, i+1)
+			return v.number(i + 1)
 		case 't':
-			return validtrue(data, i+1)
+			return v.literal(i, "true")
 		case 'f':
-			return validfalse(data, i+1)
+			return v.literal(i, "false")
 		case 'n':
-			return validnull(data, i+1)
+			return v.literal(i, "null")
 		}
 	}
-	return i, false
+	return v.eof()
 }
-func validobject[T Stringlike](data T, i int) (outi int, ok bool) {
+
+func (v *validator[T]) object(i int) (outi int, ok bool) {
+	data := v.data
 	for ; i < len(data); i++ {
 		switch data[i] {
 		default:
-			return i, false
+			return v.unexpected(i, "looking for beginning of object key string")
 		case ' ', '\t', '\n', '\r':
 			continue
 		case '}':
 			return i + 1, true
 		case '"':
+			members := 0
 		key:
-			if i, ok = validstring(data, i+1); !ok {
+			if members++; v.limits.MaxMembers > 0 && members > v.limits.MaxMembers {
+				return v.exceed(i, MemberLimit)
+			}
+			if i, ok = v.string(i + 1); !ok {
 				return i, false
 			}
-			if i, ok = validcolon(data, i); !ok {
+			if i, ok = v.colon(i); !ok {
 				return i, false
 			}
-			if i, ok = validany(data, i); !ok {
+			if i, ok = v.any(i); !ok {
 				return i, false
 			}
-			if i, ok = validcomma(data, i, '}'); !ok {
+			if i, ok = v.comma(i, '}'); !ok {
 				return i, false
 			}
 			if data[i] == '}' {
@@ -1519,36 +1855,43 @@ func validobject[T Stringlike](data T, i int) (outi int, ok bool) {
 			for ; i < len(data); i++ {
 				switch data[i] {
 				default:
-					return i, false
+					return v.unexpected(i, "looking for beginning of object key string")
 				case ' ', '\t', '\n', '\r':
 					continue
 				case '"':
 					goto key
 				}
 			}
-			return i, false
+			return v.eof()
 		}
 	}
-	return i, false
+	return v.eof()
 }
-func validcolon[T Stringlike](data T, i int) (outi int, ok bool) {
+
+func (v *validator[T]) colon(i int) (outi int, ok bool) {
+	data := v.data
 	for ; i < len(data); i++ {
 		switch data[i] {
 		default:
-			return i, false
+			return v.fail(i, "expected ':' after object key")
 		case ' ', '\t', '\n', '\r':
 			continue
 		case ':':
 			return i + 1, true
 		}
 	}
-	return i, false
+	return v.eof()
 }
-func validcomma[T Stringlike](data T, i int, end byte) (outi int, ok bool) {
+
+func (v *validator[T]) comma(i int, end byte) (outi int, ok bool) {
+	data := v.data
 	for ; i < len(data); i++ {
 		switch data[i] {
 		default:
-			return i, false
+			if end == '}' {
+				return v.fail(i, "expected ',' or '}' after object value")
+			}
+			return v.fail(i, "expected ',' or ']' after array element")
 		case ' ', '\t', '\n', '\r':
 			continue
 		case ',':
@@ -1557,17 +1900,19 @@ func validcomma[T Stringlike](data T, i int, end byte) (outi int, ok bool) {
 			return i, true
 		}
 	}
-	return i, false
+	return v.eof()
 }
-func validarray[T Stringlike](data T, i int) (outi int, ok bool) {
+
+func (v *validator[T]) array(i int) (outi int, ok bool) {
+	data := v.data
 	for ; i < len(data); i++ {
 		switch data[i] {
 		default:
 			for ; i < len(data); i++ {
-				if i, ok = validany(data, i); !ok {
+				if i, ok = v.any(i); !ok {
 					return i, false
 				}
-				if i, ok = validcomma(data, i, ']'); !ok {
+				if i, ok = v.comma(i, ']'); !ok {
 					return i, false
 				}
 				if data[i] == ']' {
@@ -1580,55 +1925,79 @@ func validarray[T Stringlike](data T, i int) (outi int, ok bool) {
 			return i + 1, true
 		}
 	}
-	return i, false
+	return v.eof()
 }
-func validstring[T Stringlike](data T, i int) (outi int, ok bool) {
+
+func (v *validator[T]) string(i int) (outi int, ok bool) {
+	data := v.data
+	start := i
 	for ; i < len(data); i++ {
 		if data[i] < ' ' {
-			return i, false
+			return v.fail(i, "invalid control character "+quoteByte(data[i])+" in string")
 		} else if data[i] == '\\' {
 			i++
 			if i == len(data) {
-				return i, false
+				return v.eof()
 			}
 			switch data[i] {
 			default:
-				return i, false
+				return v.fail(i-1, "invalid character "+quoteByte(data[i])+" in string escape code")
 			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
 			case 'u':
 				for j := 0; j < 4; j++ {
 					i++
 					if i >= len(data) {
-						return i, false
+						return v.eof()
 					}
 					if !((data[i] >= '0' && data[i] <= '9') ||
 						(data[i] >= 'a' && data[i] <= 'f') ||
 						(data[i] >= 'A' && data[i] <= 'F')) {
+						return v.unexpected(i, "in \\u escape")
+					}
+				}
+				if v.limits.Strict {
+					if i, ok = v.surrogate(i - 5); !ok {
 						return i, false
 					}
 				}
 			}
+		} else if data[i] >= utf8.RuneSelf && v.limits.Strict {
+			end := i + utf8.UTFMax
+			if end > len(data) {
+				end = len(data)
+			}
+			r, size := utf8.DecodeRuneInString(string(data[i:end]))
+			if r == utf8.RuneError && size == 1 {
+				return v.fail(i, "invalid UTF-8 in string")
+			}
+			i += size - 1
 		} else if data[i] == '"' {
+			if v.limits.MaxStringLength > 0 && i-start > v.limits.MaxStringLength {
+				return v.exceed(start-1, StringLengthLimit)
+			}
 			return i + 1, true
 		}
 	}
-	return i, false
+	return v.eof()
 }
-func validnumber[T Stringlike](data T, i int) (outi int, ok bool) {
+
+func (v *validator[T]) number(i int) (outi int, ok bool) {
+	data := v.data
 	i--
+	start := i
 	// sign
 	if data[i] == '-' {
 		i++
 		if i == len(data) {
-			return i, false
+			return v.eof()
 		}
 		if data[i] < '0' || data[i] > '9' {
-			return i, false
+			return v.unexpected(i, "in numeric literal")
 		}
 	}
 	// int
 	if i == len(data) {
-		return i, false
+		return v.eof()
 	}
 	if data[i] == '0' {
 		i++
@@ -1642,15 +2011,15 @@ func validnumber[T Stringlike](data T, i int) (outi int, ok bool) {
 	}
 	// frac
 	if i == len(data) {
-		return i, true
+		return v.numberEnd(start, i)
 	}
 	if data[i] == '.' {
 		i++
 		if i == len(data) {
-			return i, false
+			return v.eof()
 		}
 		if data[i] < '0' || data[i] > '9' {
-			return i, false
+			return v.unexpected(i, "after decimal point in numeric literal")
 		}
 		i++
 		for ; i < len(data); i++ {
@@ -1662,21 +2031,21 @@ func validnumber[T Stringlike](data T, i int) (outi int, ok bool) {
 	}
 	// exp
 	if i == len(data) {
-		return i, true
+		return v.numberEnd(start, i)
 	}
 	if data[i] == 'e' || data[i] == 'E' {
 		i++
 		if i == len(data) {
-			return i, false
+			return v.eof()
 		}
 		if data[i] == '+' || data[i] == '-' {
 			i++
 		}
 		if i == len(data) {
-			return i, false
+			return v.eof()
 		}
 		if data[i] < '0' || data[i] > '9' {
-			return i, false
+			return v.unexpected(i, "in exponent of numeric literal")
 		}
 		i++
 		for ; i < len(data); i++ {
@@ -1686,29 +2055,46 @@ func validnumber[T Stringlike](data T, i int) (outi int, ok bool) {
 			break
 		}
 	}
-	return i, true
+	return v.numberEnd(start, i)
 }
 
-func validtrue[T Stringlike](data T, i int) (outi int, ok bool) {
-	if i+3 <= len(data) && data[i] == 'r' && data[i+1] == 'u' &&
-		data[i+2] == 'e' {
-		return i + 3, true
+// surrogate checks that the \\u escape that begins at offset i is not half of
+// a surrogate pair, or that it is followed by the other half of the pair. It
+// returns the offset of the last byte of the escape or pair.
+func (v *validator[T]) surrogate(i int) (outi int, ok bool) {
+	data := v.data
+	r := runeit(data[i+2:])
+	if !utf16.IsSurrogate(r) {
+		return i + 5, true
 	}
-	return i, false
+	j := i + 6
+	if j+6 > len(data) || data[j] != '\\' || data[j+1] != 'u' ||
+		utf16.DecodeRune(r, runeit(data[j+2:])) == utf8.RuneError {
+		return v.fail(i, "invalid lone surrogate in \\u escape")
+	}
+	return j + 5, true
 }
-func validfalse[T Stringlike](data T, i int) (outi int, ok bool) {
-	if i+4 <= len(data) && data[i] == 'a' && data[i+1] == 'l' &&
-		data[i+2] == 's' && data[i+3] == 'e' {
-		return i + 4, true
+
+// numberEnd checks the length of the number that spans data[start:i].
+func (v *validator[T]) numberEnd(start, i int) (outi int, ok bool) {
+	if v.limits.MaxNumberLength > 0 && i-start > v.limits.MaxNumberLength {
+		return v.exceed(start, NumberLengthLimit)
 	}
-	return i, false
+	return i, true
 }
-func validnull[T Stringlike](data T, i int) (outi int, ok bool) {
-	if i+3 <= len(data) && data[i] == 'u' && data[i+1] == 'l' &&
-		data[i+2] == 'l' {
-		return i + 3, true
+
+// literal validates the literal true, false or null that begins at offset i.
+func (v *validator[T]) literal(i int, lit string) (outi int, ok bool) {
+	data := v.data
+	for j := 1; j < len(lit); j++ {
+		if i+j == len(data) {
+			return v.eof()
+		}
+		if data[i+j] != lit[j] {
+			return v.unexpected(i+j, "in literal "+lit+" (expecting "+strconv.QuoteRune(rune(lit[j]))+")")
+		}
 	}
-	return i, false
+	return i + len(lit), true
 }
 
 // Valid returns true if the input is valid json.
@@ -1723,6 +2109,39 @@ func Valid[T Stringlike](json T) bool {
 	return ok
 }
 
+// Validate returns nil if the input is valid json. Otherwise, it returns a
+// *SyntaxError that describes the position of the first error and the reason
+// that the input is invalid.
+func Validate[T Stringlike](json T) error {
+	return validate(json, ValidateOptions{})
+}
+
+// validate validates json subject to the given limits.
+func validate[T Stringlike](json T, limits ValidateOptions) error {
+	if limits.MaxSize > 0 && len(json) > limits.MaxSize {
+		return &LimitError{Limit: SizeLimit, Max: limits.MaxSize, Offset: limits.MaxSize}
+	}
+	v := validator[T]{data: json, limits: limits}
+	if _, ok := v.payload(0); ok {
+		return nil
+	}
+	if v.limit != 0 {
+		return &LimitError{Limit: v.limit, Max: limits.max(v.limit), Offset: v.offset}
+	}
+	return newSyntaxError(json, v.offset, v.reason)
+}
+
+// newSyntaxError returns a SyntaxError for the error at offset i in json.
+func newSyntaxError[T Stringlike](json T, i int, msg string) *SyntaxError {
+	line, start := 1, 0
+	for j := 0; j < i && j < len(json); j++ {
+		if json[j] == '\n' {
+			line, start = line+1, j+1
+		}
+	}
+	return &SyntaxError{Offset: i, Line: line, Column: i - start + 1, msg: msg}
+}
+
 func parseUint(s string) (n uint64, ok bool) {
 	var i int
 	if i == len(s) {
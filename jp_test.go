@@ -353,6 +353,108 @@ func TestForEach(t *testing.T) {
 	Parse([]byte(`{"ok":"bad`)).ForEach(nil)
 }
 
+func TestIteratorErr(t *testing.T) {
+	cases := []struct {
+		json   string
+		count  int
+		offset int
+	}{
+		{`[1,2,3]`, 3, -1},
+		{` { "a" : 1 , "b" : [2] } `, 2, -1},
+		{`[]`, 0, -1},
+		{`{}`, 0, -1},
+		{`[1,2`, 1, 4},
+		{`[1,[2,3`, 1, 7},
+		{`[[1,2]`, 0, 6},
+		{`{"a":1,"b`, 1, 7},
+		{`{"a":1,"b":"c`, 1, 11},
+		{`{"a":1,"b":`, 1, 11},
+		{`{"a":{"b":1}`, 0, 12},
+	}
+	for _, c := range cases {
+		t.Run(c.json, func(t *testing.T) {
+			count := 0
+			err := Parse(c.json).ForEachErr(func(_, _ Result[string]) bool {
+				count++
+				return true
+			})
+			assert(t, count == c.count)
+			if c.offset == -1 {
+				assert(t, err == nil)
+				return
+			}
+			serr, ok := err.(*SyntaxError)
+			if !ok {
+				t.Fatalf("expected a *SyntaxError, got %v", err)
+			}
+			if serr.Offset != c.offset {
+				t.Fatalf("expected offset %v, got %v", c.offset, serr.Offset)
+			}
+
+			// the iterator stays stopped
+			it := Parse([]byte(c.json)).Range()
+			for it.Next() {
+			}
+			assert(t, !it.Next())
+			assert(t, it.Err() != nil)
+		})
+	}
+
+	// offsets are relative to the enclosing document
+	it := Get(`{"x":[1,"2`, "/x").Range()
+	for it.Next() {
+	}
+	serr, ok := it.Err().(*SyntaxError)
+	assert(t, ok && serr.Offset == 8)
+
+	err := Parse(`[1,2,3]`).ForEachErr(func(_, _ Result[string]) bool { return false })
+	assert(t, err == nil)
+
+	// truncated or malformed members and elements are never returned
+	malformed := []struct {
+		json   string
+		count  int
+		offset int
+		msg    string
+	}{
+		{`[tru`, 0, 4, "unexpected end of JSON input"},
+		{`{"a":fals`, 0, 9, "unexpected end of JSON input"},
+		{`[nul]`, 0, 4, "invalid character ']' in literal null (expecting 'l')"},
+		{`[trux]`, 0, 4, "invalid character 'x' in literal true (expecting 'e')"},
+		{`[truex]`, 0, 5, "invalid character 'x' after array element"},
+		{`[1.5`, 0, 4, "unexpected end of JSON input"},
+		{`[1,2.5`, 1, 6, "unexpected end of JSON input"},
+		{`{"a":[1,2}`, 0, 9, "invalid character '}' after array element"},
+		{`[{"a":1]]`, 0, 7, "invalid character ']' after object value"},
+		{`[1,2}`, 1, 4, "invalid character '}' after array element"},
+		{`[1 2]`, 0, 3, "invalid character '2' after array element"},
+		{`{"a":1 "b":2}`, 0, 7, `invalid character '"' after object value`},
+		{`{"a" 1}`, 0, 5, "expected ':' after object key"},
+		{`{"a"::1}`, 0, 5, "invalid character ':' looking for beginning of value"},
+		{`{1:2}`, 0, 1, "invalid character '1' looking for beginning of object key string"},
+		{`[,1]`, 0, 1, "invalid character ',' looking for beginning of value"},
+		{`[1,]`, 1, 3, "invalid character ']' looking for beginning of value"},
+		{`{"a":1,}`, 1, 7, "invalid character '}' looking for beginning of object key string"},
+	}
+	for _, c := range malformed {
+		t.Run(c.json, func(t *testing.T) {
+			count := 0
+			err := Parse(c.json).ForEachErr(func(_, _ Result[string]) bool {
+				count++
+				return true
+			})
+			assert(t, count == c.count)
+			serr, ok := err.(*SyntaxError)
+			if !ok {
+				t.Fatalf("expected a *SyntaxError, got %v", err)
+			}
+			if serr.Offset != c.offset || serr.msg != c.msg {
+				t.Fatalf("expected %q at offset %v, got %v", c.msg, c.offset, serr)
+			}
+		})
+	}
+}
+
 func TestMap(t *testing.T) {
 	assert(t, len(Parse([]byte(`"asdf"`)).Map()) == 0)
 	assert(t, Parse([]byte(`{"asdf":"ghjk"`)).Map()["asdf"].String() ==
@@ -982,6 +1084,59 @@ func makeRandomJSONChars(b []byte) {
 	copy(b, bb[:len(b)])
 }
 
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		json   string
+		offset int
+		line   int
+		column int
+		reason string
+	}{
+		{`{"a":1}`, -1, 0, 0, ""},
+		{``, 0, 1, 1, "unexpected end of JSON input"},
+		{`   `, 3, 1, 4, "unexpected end of JSON input"},
+		{`{"a" 1}`, 5, 1, 6, "expected ':' after object key"},
+		{`{"a":1 "b":2}`, 7, 1, 8, "expected ',' or '}' after object value"},
+		{`[1 2]`, 3, 1, 4, "expected ',' or ']' after array element"},
+		{`{"a":1,}`, 7, 1, 8, "invalid character '}' looking for beginning of object key string"},
+		{`{1:2}`, 1, 1, 2, "invalid character '1' looking for beginning of object key string"},
+		{`[1,]`, 3, 1, 4, "invalid character ']' looking for beginning of value"},
+		{"[\n  \"a\\q\"\n]", 6, 2, 5, `invalid character 'q' in string escape code`},
+		{"\"\\\xe9\"", 1, 1, 2, `invalid character '\xe9' in string escape code`},
+		{`"\u12x4"`, 5, 1, 6, `invalid character 'x' in \u escape`},
+		{"\"a\tb\"", 2, 1, 3, `invalid control character '\t' in string`},
+		{`"abc`, 4, 1, 5, "unexpected end of JSON input"},
+		{`-x`, 1, 1, 2, "invalid character 'x' in numeric literal"},
+		{`1.e5`, 2, 1, 3, "invalid character 'e' after decimal point in numeric literal"},
+		{`1e+`, 3, 1, 4, "unexpected end of JSON input"},
+		{`01`, 1, 1, 2, "invalid character '1' after top-level value"},
+		{`{"a":tru}`, 8, 1, 9, "invalid character '}' in literal true (expecting 'e')"},
+		{`nul`, 3, 1, 4, "unexpected end of JSON input"},
+		{"{\n\t\"a\": [1, 2,\n\t\t3}\n}", 18, 3, 4, "expected ',' or ']' after array element"},
+		{"[\xff]", 1, 1, 2, `invalid character '\xff' looking for beginning of value`},
+		{`{} {}`, 3, 1, 4, "invalid character '{' after top-level value"},
+	}
+	for _, c := range cases {
+		t.Run(c.json, func(t *testing.T) {
+			err := Validate(c.json)
+			assert(t, (err == nil) == Valid(c.json))
+			if c.offset == -1 {
+				assert(t, err == nil)
+				return
+			}
+			serr, ok := err.(*SyntaxError)
+			if !ok {
+				t.Fatalf("expected a *SyntaxError, got %v", err)
+			}
+			expected := SyntaxError{Offset: c.offset, Line: c.line, Column: c.column, msg: c.reason}
+			if *serr != expected {
+				t.Fatalf("expected %v, got %v", expected.Error(), serr.Error())
+			}
+			assert(t, Validate([]byte(c.json)).Error() == err.Error())
+		})
+	}
+}
+
 func TestValidRandom(t *testing.T) {
 	rand.Seed(time.Now().UnixNano())
 	b := make([]byte, 100000)
@@ -1252,6 +1407,68 @@ func TestParseIndex(t *testing.T) {
 	assert(t, Parse(` -inf`).Index == 1)
 }
 
+func TestParseBounds(t *testing.T) {
+	value := Parse(` {"a":[1,2],"b":"}"} trailing`)
+	assert(t, value.Raw == `{"a":[1,2],"b":"}"}`)
+	assert(t, value.Len() == 2)
+	assert(t, Parse(`[1,[2,3],{"a":4}]`).Len() == 3)
+	assert(t, Parse(`[]`).Len() == 0)
+	assert(t, Parse(`[ ]`).Len() == 0)
+	assert(t, Parse(`[1]`).Len() == 1)
+	assert(t, Parse(`[null]`).Len() == 1)
+	assert(t, Parse(`[-1]`).Len() == 1)
+	assert(t, Parse(`[true]`).Len() == 1)
+	assert(t, Parse(`{ }`).Len() == 0)
+	assert(t, Parse([]byte(`[ 1 ]`)).Len() == 1)
+	assert(t, Get(`{"a":[1]}`, "/a").Len() == 1)
+	assert(t, Get(`{"a":[[1],[]]}`, "/a/0").Len() == 1)
+	assert(t, Get(`{"a":[[1],[]]}`, "/a/1").Len() == 0)
+	doc, err := NewDocument(`{"a":[1]}`)
+	assert(t, err == nil)
+	assert(t, doc.Get("/a").Len() == Get(`{"a":[1]}`, "/a").Len())
+	assert(t, Parse([]byte(`[1,2]{}`)).Len() == 2)
+	assert(t, string(Parse([]byte(`[1,2]{}`)).Raw) == `[1,2]`)
+}
+
+func TestNextValue(t *testing.T) {
+	json := ` {"a":1}{"b":[2]} 3 "four"[5,6]true  null `
+	expected := []struct {
+		raw   string
+		index int
+	}{
+		{`{"a":1}`, 1},
+		{`{"b":[2]}`, 8},
+		{`3`, 18},
+		{`"four"`, 20},
+		{`[5,6]`, 26},
+		{`true`, 31},
+		{`null`, 37},
+	}
+
+	i := 0
+	for _, e := range expected {
+		var value Result[string]
+		value, i = NextValue(json, i)
+		assert(t, value.Raw == e.raw)
+		assert(t, value.Index == e.index)
+		assert(t, i == e.index+len(e.raw))
+	}
+	value, end := NextValue(json, i)
+	assert(t, !value.Exists())
+	assert(t, end == i)
+
+	values := ParseAll([]byte(json))
+	assert(t, len(values) == len(expected))
+	for j, e := range expected {
+		assert(t, string(values[j].Raw) == e.raw)
+		assert(t, values[j].Index == e.index)
+	}
+	assert(t, values[1].Get("/b/0").Int() == 2)
+
+	assert(t, len(ParseAll(`1 2 ] 3`)) == 2)
+	assert(t, len(ParseAll(``)) == 0)
+}
+
 const readmeJSON = `
 {
   "name": {"first": "Tom", "last": "Anderson"},
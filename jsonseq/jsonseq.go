@@ -0,0 +1,200 @@
+// Package jsonseq reads and writes RFC 7464 JSON text sequences
+// (application/json-seq), in which each JSON text is preceded by an ASCII
+// record separator (0x1E) and followed by a line feed.
+package jsonseq
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/pgavlin/jp/v3"
+)
+
+// RS is the ASCII record separator that precedes each JSON text.
+const RS = 0x1E
+
+// Options controls how a Reader processes records.
+type Options struct {
+	// Pointers, if non-nil, is resolved against each record. The results are
+	// available from the Reader's Values method.
+	Pointers *jp.PointerSet
+}
+
+// Reader reads the JSON texts of an RFC 7464 sequence from an io.Reader.
+//
+// As required by RFC 7464, records that are truncated or otherwise malformed
+// are skipped rather than treated as fatal errors. The number of records
+// that were skipped is available from the Skipped method.
+type Reader struct {
+	r       *bufio.Reader
+	options Options
+	buf     []byte
+	started bool
+
+	record  jp.Result[[]byte]
+	values  []jp.Result[[]byte]
+	index   int
+	skipped int
+	err     error
+	done    bool
+}
+
+// NewReader returns a Reader that reads records from r.
+func NewReader(r io.Reader) *Reader {
+	return NewReaderWithOptions(r, Options{})
+}
+
+// NewReaderWithOptions returns a Reader that reads records from r using the
+// given options.
+func NewReaderWithOptions(r io.Reader, options Options) *Reader {
+	return &Reader{r: bufio.NewReader(r), options: options, index: -1}
+}
+
+// Next advances the reader to the next well-formed record. It returns false
+// at the end of the input or if the underlying reader returns an error. In
+// the latter case, Err returns the error.
+func (r *Reader) Next() bool {
+	r.record, r.values = jp.Result[[]byte]{}, nil
+	for !r.done {
+		data, err := r.next()
+		if err != nil {
+			if err != io.EOF {
+				r.err = err
+			}
+			r.done = true
+		}
+		if data == nil {
+			continue
+		}
+
+		r.index++
+		if !complete(data) {
+			r.skipped++
+			continue
+		}
+		data = bytes.TrimSpace(data)
+		r.record = jp.Parse(data)
+		if r.options.Pointers != nil {
+			r.values = r.options.Pointers.GetBytes(data)
+		}
+		return true
+	}
+	return false
+}
+
+// Record returns the current record. The record's Raw field refers to the
+// reader's buffer, and is only valid until the next call to Next.
+func (r *Reader) Record() jp.Result[[]byte] {
+	return r.record
+}
+
+// Get searches the current record for the specified pointer. The pointer is
+// interpreted as it would be by jp.Get.
+func (r *Reader) Get(pointer string) jp.Result[[]byte] {
+	return jp.Get(r.record.Raw, pointer)
+}
+
+// Values returns the results of resolving the reader's pointers against the
+// current record, in the same order as the pointers. It returns nil if the
+// reader has no pointers.
+func (r *Reader) Values() []jp.Result[[]byte] {
+	return r.values
+}
+
+// Index returns the 0-based position of the current record within the
+// sequence. Skipped records are included in the count.
+func (r *Reader) Index() int {
+	return r.index
+}
+
+// Skipped returns the number of truncated or malformed records that have been
+// skipped.
+func (r *Reader) Skipped() int {
+	return r.skipped
+}
+
+// Err returns the error, if any, returned by the underlying reader.
+func (r *Reader) Err() error {
+	return r.err
+}
+
+// next returns the contents of the next record, excluding its record
+// separator. It returns nil if the input that it read does not form a
+// record, i.e. if it precedes the first record separator or is empty.
+func (r *Reader) next() ([]byte, error) {
+	r.buf = r.buf[:0]
+
+	var data []byte
+	var err error
+	for {
+		data, err = r.r.ReadSlice(RS)
+		if err != bufio.ErrBufferFull {
+			break
+		}
+		r.buf = append(r.buf, data...)
+	}
+	if len(r.buf) != 0 {
+		r.buf = append(r.buf, data...)
+		data = r.buf
+	}
+	if err == nil {
+		data = data[:len(data)-1]
+	}
+
+	if !r.started {
+		// text that precedes the first record separator is not a record
+		r.started = true
+		if len(bytes.TrimSpace(data)) != 0 {
+			r.skipped++
+		}
+		return nil, err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, err
+	}
+	return data, err
+}
+
+// complete returns true if data holds a single well-formed JSON text. As
+// described in RFC 7464 section 2.4, a top-level number, true, false or null
+// that is not followed by whitespace may have been truncated, and is not
+// considered complete.
+func complete(data []byte) bool {
+	if !jp.Valid(data) {
+		return false
+	}
+	switch bytes.TrimSpace(data)[0] {
+	case '{', '[', '"':
+		return true
+	default:
+		c := data[len(data)-1]
+		return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+	}
+}
+
+// Writer writes the JSON texts of an RFC 7464 sequence to an io.Writer.
+type Writer struct {
+	w   io.Writer
+	buf []byte
+}
+
+// NewWriter returns a Writer that writes records to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// WriteRecord writes a single JSON text to the sequence, preceded by a record
+// separator and followed by a line feed. An error is returned if the text is
+// not valid JSON.
+func (w *Writer) WriteRecord(json []byte) error {
+	if err := jp.Validate(json); err != nil {
+		return fmt.Errorf("jsonseq: %w", err)
+	}
+	w.buf = append(w.buf[:0], RS)
+	w.buf = append(w.buf, bytes.TrimSpace(json)...)
+	w.buf = append(w.buf, '\n')
+	_, err := w.w.Write(w.buf)
+	return err
+}
@@ -0,0 +1,96 @@
+package jsonseq
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/pgavlin/jp/v3"
+)
+
+func TestReader(t *testing.T) {
+	input := "\x1e{\"a\":1}\n\x1e[1,\n2]\n\x1e\"three\"\n\x1e\x1e4\n\x1etrue\n\x1e{\"a\":{\"b\":5}}\n"
+	expected := []string{`{"a":1}`, "[1,\n2]", `"three"`, `4`, `true`, `{"a":{"b":5}}`}
+
+	for _, r := range []io.Reader{strings.NewReader(input), iotest.OneByteReader(strings.NewReader(input))} {
+		reader := NewReader(r)
+		i := 0
+		for reader.Next() {
+			if string(reader.Record().Raw) != expected[i] {
+				t.Fatalf("record %v: expected %s, got %s", i, expected[i], reader.Record().Raw)
+			}
+			assert(t, reader.Index() == i)
+			i++
+		}
+		assert(t, reader.Err() == nil)
+		assert(t, reader.Skipped() == 0)
+		assert(t, i == len(expected))
+	}
+}
+
+func TestReaderTruncated(t *testing.T) {
+	// RFC 7464, section 2.4: the truncated 123 and {"a": are skipped, as is
+	// the text before the first record separator
+	input := "junk\x1e123\x1e{\"a\":\x1e\"ok\"\n\x1etrue\x1e[1]\n\x1e456"
+	reader := NewReader(strings.NewReader(input))
+
+	var raws []string
+	var indices []int
+	for reader.Next() {
+		raws = append(raws, string(reader.Record().Raw))
+		indices = append(indices, reader.Index())
+	}
+	assert(t, reader.Err() == nil)
+	assert(t, strings.Join(raws, ",") == `"ok",[1]`)
+	assert(t, indices[0] == 2 && indices[1] == 4)
+	assert(t, reader.Skipped() == 5)
+}
+
+func TestReaderPointers(t *testing.T) {
+	input := "\x1e{\"id\":1,\"name\":\"a\"}\n\x1e{\"id\":2}\n"
+	set, err := jp.ParsePointerSet("/id", "/name")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader := NewReaderWithOptions(strings.NewReader(input), Options{Pointers: set})
+	assert(t, reader.Next())
+	assert(t, reader.Values()[0].Int() == 1 && reader.Values()[1].String() == "a")
+	assert(t, reader.Get("/name").String() == "a")
+	assert(t, reader.Next())
+	assert(t, reader.Values()[0].Int() == 2 && !reader.Values()[1].Exists())
+	assert(t, !reader.Next())
+}
+
+func TestReaderError(t *testing.T) {
+	readErr := errors.New("boom")
+	reader := NewReader(io.MultiReader(strings.NewReader("\x1e1\n\x1e2\n"), iotest.ErrReader(readErr)))
+	assert(t, reader.Next() && reader.Next())
+	assert(t, !reader.Next())
+	assert(t, reader.Err() == readErr)
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	assert(t, w.WriteRecord([]byte(` {"a":1} `)) == nil)
+	assert(t, w.WriteRecord([]byte(`2`)) == nil)
+	assert(t, w.WriteRecord([]byte(`{`)) != nil)
+	assert(t, buf.String() == "\x1e{\"a\":1}\n\x1e2\n")
+
+	reader := NewReader(&buf)
+	assert(t, reader.Next() && reader.Record().Get("/a").Int() == 1)
+	assert(t, reader.Next() && reader.Record().Int() == 2)
+	assert(t, !reader.Next())
+	assert(t, reader.Skipped() == 0)
+}
+
+func assert(t testing.TB, cond bool) {
+	t.Helper()
+	if !cond {
+		t.Fatal("assert failed")
+	}
+}
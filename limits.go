@@ -0,0 +1,127 @@
+package jp
+
+import "fmt"
+
+// ValidateOptions controls how documents are validated: the dialect of JSON
+// that is accepted, how strictly documents are checked, and the resources that
+// validation may consume. A limit of zero means that the corresponding
+// property is unbounded.
+type ValidateOptions struct {
+	// Strict rejects documents that are accepted by Valid but are not
+	// interoperable RFC 8259 JSON: strings that contain invalid UTF-8, and \u
+	// escapes that encode a lone UTF-16 surrogate. Non-standard numbers such
+	// as NaN, Infinity and +1 are always rejected by validation, although Get
+	// and Parse accept them.
+	Strict bool
+	// Dialect is the dialect of JSON that is accepted. The default is
+	// DialectJSON.
+	Dialect Dialect
+
+	// MaxDepth is the maximum nesting depth of objects and arrays. A
+	// top-level object or array has depth 1.
+	MaxDepth int
+	// MaxSize is the maximum size of the document in bytes.
+	MaxSize int
+	// MaxStringLength is the maximum length in bytes of a string or object
+	// key, excluding its quotes. The length is measured before escape
+	// sequences are decoded.
+	MaxStringLength int
+	// MaxMembers is the maximum number of members in a single object.
+	MaxMembers int
+	// MaxNumberLength is the maximum length in bytes of a number.
+	MaxNumberLength int
+}
+
+// max returns the maximum for the given limit.
+func (o ValidateOptions) max(limit Limit) int {
+	switch limit {
+	case DepthLimit:
+		return o.MaxDepth
+	case SizeLimit:
+		return o.MaxSize
+	case StringLengthLimit:
+		return o.MaxStringLength
+	case MemberLimit:
+		return o.MaxMembers
+	case NumberLengthLimit:
+		return o.MaxNumberLength
+	default:
+		return 0
+	}
+}
+
+// Limit identifies a limit in ValidateOptions.
+type Limit int
+
+const (
+	// DepthLimit is the limit on nesting depth.
+	DepthLimit Limit = iota + 1
+	// SizeLimit is the limit on document size.
+	SizeLimit
+	// StringLengthLimit is the limit on string length.
+	StringLengthLimit
+	// MemberLimit is the limit on the number of members in an object.
+	MemberLimit
+	// NumberLengthLimit is the limit on number length.
+	NumberLengthLimit
+)
+
+// String returns a description of the limit.
+func (l Limit) String() string {
+	switch l {
+	default:
+		return ""
+	case DepthLimit:
+		return "nesting depth"
+	case SizeLimit:
+		return "document size"
+	case StringLengthLimit:
+		return "string length"
+	case MemberLimit:
+		return "object members"
+	case NumberLengthLimit:
+		return "number length"
+	}
+}
+
+// LimitError describes a document that exceeds a limit in ValidateOptions.
+type LimitError struct {
+	// Limit is the limit that was exceeded.
+	Limit Limit
+	// Max is the value of the limit.
+	Max int
+	// Offset is the byte offset of the value that exceeded the limit. For
+	// SizeLimit, Offset is the offset of the first byte past the limit.
+	Offset int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("JSON exceeds maximum %v of %d at offset %d", e.Limit, e.Max, e.Offset)
+}
+
+// ValidateWithOptions returns nil if the input is valid json that is within
+// the limits given by opts. If the input is not valid, it returns a
+// *SyntaxError; if it exceeds a limit, it returns a *LimitError. Limits are
+// enforced as the document is scanned, so validation stops as soon as a limit
+// is exceeded. A nil opts imposes no limits.
+//
+// If opts specifies a dialect other than DialectJSON, the limits other than
+// MaxSize apply to the document's translation to JSON. See ParseWithOptions.
+func ValidateWithOptions[T Stringlike](json T, opts *ValidateOptions) error {
+	_, err := normalize(json, opts)
+	return err
+}
+
+// GetWithOptions validates json subject to opts as ValidateWithOptions does,
+// and then searches it for the specified pointer as Get does. It is suitable
+// for use on untrusted input. An error is returned if json is invalid or
+// exceeds a limit; a pointer that does not resolve is not an error, and
+// results in a Result that does not exist. Documents in dialects other than
+// DialectJSON are translated as they are by ParseWithOptions.
+func GetWithOptions[T Stringlike](json T, pointer string, opts *ValidateOptions) (Result[T], error) {
+	json, err := normalize(json, opts)
+	if err != nil {
+		return Result[T]{}, err
+	}
+	return Get(json, pointer), nil
+}
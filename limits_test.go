@@ -0,0 +1,158 @@
+package jp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateWithOptions(t *testing.T) {
+	tests := []struct {
+		json   string
+		opts   ValidateOptions
+		limit  Limit
+		offset int
+	}{
+		{`[[[]]]`, ValidateOptions{MaxDepth: 3}, 0, 0},
+		{`[[[[]]]]`, ValidateOptions{MaxDepth: 3}, DepthLimit, 3},
+		{`{"a":{"b":[1]}}`, ValidateOptions{MaxDepth: 2}, DepthLimit, 10},
+		{`[{},{},[]]`, ValidateOptions{MaxDepth: 2}, 0, 0},
+		{`"abc"`, ValidateOptions{MaxSize: 5}, 0, 0},
+		{`"abcd"`, ValidateOptions{MaxSize: 5}, SizeLimit, 5},
+		{`["abc"]`, ValidateOptions{MaxStringLength: 3}, 0, 0},
+		{`["abcd"]`, ValidateOptions{MaxStringLength: 3}, StringLengthLimit, 1},
+		{`{"abcd":1}`, ValidateOptions{MaxStringLength: 3}, StringLengthLimit, 1},
+		{`{"a":1,"b":2}`, ValidateOptions{MaxMembers: 2}, 0, 0},
+		{`{"a":1,"b":2,"c":3}`, ValidateOptions{MaxMembers: 2}, MemberLimit, 13},
+		{`{"a":{"x":1,"y":2},"b":{"z":3}}`, ValidateOptions{MaxMembers: 2}, 0, 0},
+		{`[1,2,3,4]`, ValidateOptions{MaxMembers: 2}, 0, 0},
+		{`[-1.5e10]`, ValidateOptions{MaxNumberLength: 7}, 0, 0},
+		{`[1, -1.5e100]`, ValidateOptions{MaxNumberLength: 7}, NumberLengthLimit, 4},
+		{`12345678`, ValidateOptions{MaxNumberLength: 7}, NumberLengthLimit, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.json, func(t *testing.T) {
+			err := ValidateWithOptions(tt.json, &tt.opts)
+			if tt.limit == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var lerr *LimitError
+			if !errors.As(err, &lerr) {
+				t.Fatalf("expected *LimitError, got %v", err)
+			}
+			if lerr.Limit != tt.limit || lerr.Offset != tt.offset || lerr.Max != tt.opts.max(tt.limit) {
+				t.Fatalf("expected %v limit at offset %d, got %#v", tt.limit, tt.offset, lerr)
+			}
+		})
+	}
+}
+
+func TestValidateWithOptionsSyntaxError(t *testing.T) {
+	err := ValidateWithOptions(`[1,]`, &ValidateOptions{MaxDepth: 4})
+	var serr *SyntaxError
+	if !errors.As(err, &serr) {
+		t.Fatalf("expected *SyntaxError, got %v", err)
+	}
+	if err := ValidateWithOptions(`[1,]`, nil); err == nil {
+		t.Fatal("expected error")
+	}
+	if err := ValidateWithOptions(strings.Repeat("[", 1000)+strings.Repeat("]", 1000), nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateWithOptionsDeepNesting(t *testing.T) {
+	json := strings.Repeat(`{"a":[`, 1<<16)
+	err := ValidateWithOptions(json, &ValidateOptions{MaxDepth: 64})
+	var lerr *LimitError
+	if !errors.As(err, &lerr) || lerr.Limit != DepthLimit || lerr.Offset != 6*32 {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGetWithOptions(t *testing.T) {
+	opts := &ValidateOptions{MaxDepth: 3, MaxSize: 1024}
+	json := `{"name":{"first":"Tom","last":"Anderson"},"friends":[{"first":"Dale"}]}`
+	value, err := GetWithOptions(json, "/friends/0/first", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value.Str != "Dale" {
+		t.Fatalf("expected Dale, got %v", value)
+	}
+
+	value, err = GetWithOptions(json, "/missing", opts)
+	if err != nil || value.Exists() {
+		t.Fatalf("expected missing value, got %v, %v", value, err)
+	}
+
+	_, err = GetWithOptions(`{"a":[[[[1]]]]}`, "/a", opts)
+	var lerr *LimitError
+	if !errors.As(err, &lerr) || lerr.Limit != DepthLimit {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = GetWithOptions(json, "/name", &ValidateOptions{MaxStringLength: 5})
+	if !errors.As(err, &lerr) || lerr.Limit != StringLengthLimit {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lerr.Error() != "JSON exceeds maximum string length of 5 at offset 30" {
+		t.Fatalf("unexpected message: %v", lerr)
+	}
+}
+
+func TestValidateStrict(t *testing.T) {
+	tests := []struct {
+		json   string
+		valid  bool
+		offset int
+	}{
+		{`"héllo, 世界 😀"`, true, 0},
+		{`"é😀"`, true, 0},
+		{`{"😀":"￿"}`, true, 0},
+		{`"\ud83d\ude00 \uD83D\uDE00 \ufffd"`, true, 0},
+		{"\"\xff\"", false, 1},
+		{"[\"ok\", \"\xe4\xb8\"]", false, 8},
+		{"\"\xed\xa0\x80\"", false, 1},
+		{`"\ud83d"`, false, 1},
+		{`"\ud83dx"`, false, 1},
+		{`"\ud83dA"`, false, 1},
+		{`"\ude00\ud83d"`, false, 1},
+		{`["a", "b\udc00"]`, false, 8},
+		{`NaN`, false, 0},
+		{`[Infinity]`, false, 1},
+		{`-Infinity`, false, 1},
+		{`+1`, false, 0},
+		{`{"a":+1}`, false, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.json, func(t *testing.T) {
+			err := ValidateWithOptions(tt.json, &ValidateOptions{Strict: true})
+			if tt.valid {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var serr *SyntaxError
+			if !errors.As(err, &serr) {
+				t.Fatalf("expected *SyntaxError, got %v", err)
+			}
+			if serr.Offset != tt.offset {
+				t.Fatalf("expected offset %d, got %v", tt.offset, serr)
+			}
+		})
+	}
+
+	// without Strict, invalid UTF-8 and lone surrogates are accepted
+	for _, json := range []string{"\"\xff\"", `"\ud83d"`, `"\ude00"`} {
+		if err := ValidateWithOptions(json, &ValidateOptions{}); err != nil {
+			t.Fatalf("unexpected error for %q: %v", json, err)
+		}
+	}
+}
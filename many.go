@@ -0,0 +1,449 @@
+package jp
+
+// PointerSet is a precompiled set of pointers that are resolved together in a
+// single pass over a document. The pointers are arranged in a trie so that
+// subtrees of the document that no pointer touches are skipped, and the scan
+// stops as soon as every pointer has been resolved.
+//
+// A PointerSet is safe for concurrent use.
+type PointerSet struct {
+	root pointerNode
+	// nodes is the number of nodes in the trie, excluding the root.
+	nodes  int
+	strict []bool
+	// free holds preallocated nodes so that the trie is not built with an
+	// allocation per node.
+	free []pointerNode
+}
+
+// pointerNode is a node in a PointerSet's trie. Each node corresponds to a
+// prefix of one or more of the set's pointers.
+type pointerNode struct {
+	id     int
+	parent *pointerNode
+	token  referenceToken
+
+	// results holds the positions of the pointers that end at this node.
+	results []int
+	// size is the number of pointers that end at or below this node.
+	size int
+
+	children []*pointerNode
+	// byKey and byIndex index children by key and by array index. They are
+	// only populated for nodes with many children.
+	byKey   map[string][]*pointerNode
+	byIndex map[int][]*pointerNode
+}
+
+// pointerNodeIndexThreshold is the number of children at which a node's
+// children are indexed by maps rather than searched linearly.
+const pointerNodeIndexThreshold = 8
+
+// NewPointerSet returns a PointerSet for the given pointers. The results
+// returned by the set's Get and GetBytes methods are in the same order as the
+// pointers.
+func NewPointerSet(pointers ...Pointer) *PointerSet {
+	s := &PointerSet{}
+	for _, p := range pointers {
+		s.add(p)
+	}
+	return s
+}
+
+// ParsePointerSet compiles the given pointers with ParsePointer and returns a
+// PointerSet for them.
+func ParsePointerSet(pointers ...string) (*PointerSet, error) {
+	s := &PointerSet{}
+	for _, pointer := range pointers {
+		p, err := ParsePointer(pointer)
+		if err != nil {
+			return nil, err
+		}
+		s.add(p)
+	}
+	return s, nil
+}
+
+// Len returns the number of pointers in the set.
+func (s *PointerSet) Len() int {
+	return len(s.strict)
+}
+
+// Get resolves each pointer in the set against json. The return value is a
+// Result array where the number of items will be equal to the number of
+// pointers in the set.
+//
+// This function expects that the json is well-formed, and does not validate.
+// Invalid json will not panic, but it may return back unexpected results.
+func (s *PointerSet) Get(json string) []Result[string] {
+	return getMany(json, s)
+}
+
+// GetBytes resolves each pointer in the set against json. The return value is
+// a Result array where the number of items will be equal to the number of
+// pointers in the set.
+//
+// This function expects that the json is well-formed, and does not validate.
+// Invalid json will not panic, but it may return back unexpected results.
+func (s *PointerSet) GetBytes(json []byte) []Result[[]byte] {
+	return getMany(json, s)
+}
+
+func (s *PointerSet) add(p Pointer) {
+	result := len(s.strict)
+	s.strict = append(s.strict, p.strict)
+
+	n := &s.root
+	n.size++
+	for _, t := range p.tokens {
+		child := n.child(t)
+		if child == nil {
+			child = s.newNode(n, t)
+			n.addChild(child)
+		}
+		n = child
+		n.size++
+	}
+	n.results = append(n.results, result)
+}
+
+// newNode returns a new node for the given token.
+func (s *PointerSet) newNode(parent *pointerNode, t referenceToken) *pointerNode {
+	if len(s.free) == 0 {
+		s.free = make([]pointerNode, pointerNodeIndexThreshold*(1+s.nodes/pointerNodeIndexThreshold))
+	}
+	s.nodes++
+	n := &s.free[0]
+	s.free = s.free[1:]
+	n.id, n.parent, n.token = s.nodes, parent, t
+	return n
+}
+
+// child returns n's child for the given token, if any.
+func (n *pointerNode) child(t referenceToken) *pointerNode {
+	children := n.children
+	if n.byKey != nil {
+		children = n.byKey[t.key]
+	}
+	for _, c := range children {
+		if c.token == t {
+			return c
+		}
+	}
+	return nil
+}
+
+func (n *pointerNode) addChild(child *pointerNode) {
+	n.children = append(n.children, child)
+	switch {
+	case len(n.children) > pointerNodeIndexThreshold:
+		n.byKey[child.token.key] = append(n.byKey[child.token.key], child)
+		n.byIndex[child.token.index] = append(n.byIndex[child.token.index], child)
+	case len(n.children) == pointerNodeIndexThreshold:
+		n.byKey = map[string][]*pointerNode{}
+		n.byIndex = map[int][]*pointerNode{}
+		for _, c := range n.children {
+			n.byKey[c.token.key] = append(n.byKey[c.token.key], c)
+			n.byIndex[c.token.index] = append(n.byIndex[c.token.index], c)
+		}
+	}
+}
+
+// manyContext holds the state of a single pass over a document.
+type manyContext[T Stringlike] struct {
+	json    T
+	set     *PointerSet
+	results []Result[T]
+	// pending holds the number of unresolved pointers at or below each node,
+	// indexed by node ID.
+	pending []int
+}
+
+func getMany[T Stringlike](json T, s *PointerSet) []Result[T] {
+	m := manyContext[T]{
+		json:    json,
+		set:     s,
+		results: make([]Result[T], len(s.strict)),
+		pending: make([]int, s.nodes+1),
+	}
+	m.initPending(&s.root)
+
+	root := &s.root
+	if len(root.results) != 0 {
+		_, result, _ := parseAny(json, 0, true)
+		m.resolve(root, result)
+	}
+	if m.pending[root.id] == 0 {
+		return m.results
+	}
+	for i := 0; i < len(json); i++ {
+		if json[i] == '{' {
+			m.object(i+1, root)
+			break
+		}
+		if json[i] == '[' {
+			m.array(i, root)
+			break
+		}
+	}
+	return m.results
+}
+
+func (m *manyContext[T]) initPending(n *pointerNode) {
+	m.pending[n.id] = n.size
+	for _, c := range n.children {
+		m.initPending(c)
+	}
+}
+
+// done returns true if every pointer in the set has been resolved.
+func (m *manyContext[T]) done() bool {
+	return m.pending[0] == 0
+}
+
+// resolve records value as the result of each pointer that ends at n. If a
+// pointer has already been resolved (e.g. due to duplicate keys), its
+// existing result is kept.
+func (m *manyContext[T]) resolve(n *pointerNode, value Result[T]) {
+	if !value.Exists() {
+		return
+	}
+	for _, r := range n.results {
+		if m.results[r].Exists() {
+			continue
+		}
+		m.results[r] = value
+		for p := n; p != nil; p = p.parent {
+			m.pending[p.id]--
+		}
+	}
+}
+
+// resolveAll records value as the result of each non-strict pointer that ends
+// at or below n.
+func (m *manyContext[T]) resolveAll(n *pointerNode, value Result[T]) {
+	for _, r := range n.results {
+		if m.set.strict[r] || m.results[r].Exists() {
+			continue
+		}
+		m.results[r] = value
+		for p := n; p != nil; p = p.parent {
+			m.pending[p.id]--
+		}
+	}
+	for _, c := range n.children {
+		m.resolveAll(c, value)
+	}
+}
+
+// skipToValue returns the offset of the start of the next value at or after
+// i, skipping whitespace, separators, and any other junk in the same way as
+// parseObject and parseArray.
+func (m *manyContext[T]) skipToValue(i int) int {
+	for ; i < len(m.json); i++ {
+		switch m.json[i] {
+		case '"', '{', '[', 'n', 't', 'f', '+', '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9',
+			'i', 'I', 'N':
+			return i
+		}
+	}
+	return i
+}
+
+// value resolves the pointers at or below n against the value that begins at
+// i. It returns the offset just past the end of the value, and false if the
+// value is malformed.
+func (m *manyContext[T]) value(i int, n *pointerNode) (int, bool) {
+	descend := len(n.children) != 0 && i < len(m.json) && (m.json[i] == '{' || m.json[i] == '[')
+	if len(n.results) == 0 && descend {
+		if m.json[i] == '{' {
+			return m.object(i+1, n)
+		}
+		return m.array(i, n)
+	}
+
+	end, value, ok := parseAny(m.json, i, true)
+	if !ok {
+		return end, false
+	}
+	value.Index = i
+	m.resolve(n, value)
+	if descend && m.pending[n.id] != 0 {
+		// descend within the bounds of the value that was just parsed
+		if m.json[i] == '{' {
+			m.object(i+1, n)
+		} else {
+			m.array(i, n)
+		}
+	}
+	return end, true
+}
+
+// skipValue skips the value that begins at i without materializing it. It
+// returns the offset just past the end of the value, and false if the value
+// is malformed.
+func (m *manyContext[T]) skipValue(i int) (int, bool) {
+	if i == len(m.json) {
+		return i, false
+	}
+	switch m.json[i] {
+	case '"':
+		i, _, _, ok := parseString(m.json, i+1)
+		return i, ok
+	case '{', '[':
+		i, _, _ = parseSquash(m.json, i)
+	case 't', 'f':
+		i, _ = parseLiteral(m.json, i)
+	case 'n':
+		if i+1 < len(m.json) && m.json[i+1] != 'u' {
+			i, _ = parseNumber(m.json, i)
+		} else {
+			i, _ = parseLiteral(m.json, i)
+		}
+	default:
+		i, _ = parseNumber(m.json, i)
+	}
+	return i, true
+}
+
+// skipRest skips the remainder of the object or array that contains offset
+// i, and returns the offset just past its end.
+func (m *manyContext[T]) skipRest(i int) int {
+	i, _, _ = parseSquash(m.json, i-1)
+	return i
+}
+
+// object resolves the pointers below n against the members of the object
+// whose first member begins at or after i. It returns the offset just past
+// the end of the object, and false if the object is malformed.
+func (m *manyContext[T]) object(i int, n *pointerNode) (int, bool) {
+	for i < len(m.json) {
+		for ; i < len(m.json); i++ {
+			if m.json[i] == '"' {
+				break
+			}
+			if m.json[i] == '}' {
+				return i + 1, true
+			}
+		}
+		if i == len(m.json) {
+			break
+		}
+
+		var key T
+		var kesc, ok bool
+		i, key, kesc, ok = parseString(m.json, i+1)
+		if !ok {
+			return i, false
+		}
+		key = key[1 : len(key)-1]
+
+		var k string
+		if kesc {
+			k = unescape(key)
+		}
+		candidates := n.children
+		if n.byKey != nil {
+			if kesc {
+				candidates = n.byKey[k]
+			} else {
+				candidates = n.byKey[string(key)]
+			}
+		}
+
+		i = m.skipToValue(i)
+		start := i
+		matched := false
+		for _, c := range candidates {
+			if kesc && c.token.key != k || !kesc && c.token.key != string(key) {
+				continue
+			}
+			if m.pending[c.id] != 0 {
+				matched = true
+				if i, ok = m.value(start, c); !ok {
+					return i, false
+				}
+			}
+		}
+		if !matched {
+			if i, ok = m.skipValue(start); !ok {
+				return i, false
+			}
+		}
+
+		if m.done() {
+			return i, true
+		}
+		if m.pending[n.id] == 0 {
+			return m.skipRest(i), true
+		}
+	}
+	return i, false
+}
+
+// array resolves the pointers below n against the elements of the array that
+// begins at offset s. It returns the offset just past the end of the array,
+// and false if the array is malformed.
+func (m *manyContext[T]) array(s int, n *pointerNode) (int, bool) {
+	// tokens that are not array indices refer to the entire array
+	for _, c := range n.children {
+		if c.token.index == -1 && m.pending[c.id] != 0 {
+			end, raw, count := parseSquash(m.json, s)
+			m.resolveAll(c, Result[T]{Type: JSON, Raw: raw, Index: s, len: count})
+			if m.done() {
+				return end, true
+			}
+			if m.pending[n.id] == 0 {
+				return end, true
+			}
+		}
+	}
+
+	i := s + 1
+	for h := 0; i < len(m.json); h++ {
+		for ; i < len(m.json); i++ {
+			if c := m.json[i]; c > ' ' && c != ',' {
+				break
+			}
+		}
+		if i == len(m.json) {
+			break
+		}
+		if m.json[i] == ']' {
+			return i + 1, true
+		}
+
+		candidates := n.children
+		if n.byIndex != nil {
+			candidates = n.byIndex[h]
+		}
+
+		i = m.skipToValue(i)
+		start := i
+		matched, ok := false, true
+		for _, c := range candidates {
+			if c.token.index != h {
+				continue
+			}
+			if m.pending[c.id] != 0 {
+				matched = true
+				if i, ok = m.value(start, c); !ok {
+					return i, false
+				}
+			}
+		}
+		if !matched {
+			if i, ok = m.skipValue(start); !ok {
+				return i, false
+			}
+		}
+
+		if m.done() {
+			return i, true
+		}
+		if m.pending[n.id] == 0 {
+			return m.skipRest(i), true
+		}
+	}
+	return i, false
+}
@@ -0,0 +1,170 @@
+package jp
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestGetManyMatchesGet(t *testing.T) {
+	cases := []struct {
+		json     string
+		pointers []string
+	}{
+		{basicJSON, []string{
+			"",
+			"/age",
+			"/name",
+			"/name/here",
+			"/name/first",
+			"/noop/what is a wren?",
+			"/items/3",
+			"/items/3/tags/2",
+			"/items/3/points/1/0",
+			"/items/3/points/x",
+			"/items/7",
+			"/items/8",
+			"/arr/3/hello",
+			"/loggy/programmers/1/firstName",
+			"/loggy/programmers/3/firstName",
+			"/loggy/programmers/3",
+			"/lastly/yay",
+			"/missing",
+			"/age/missing",
+		}},
+		{complicatedJSON, []string{
+			"/nestedTagged/Map/and",
+			"/nestedTagged/Uints/Float64",
+			"/nestedTagged/Uints/Int16",
+			"/SelfSlice/0/nestedTagged/Ints/Uint32",
+			"/NonBinary/3",
+			"/Array",
+			"/Array/0",
+			"/Array/4",
+			"/Nested",
+			"/Nested/yellow",
+		}},
+		{manyJSON, []string{
+			"/position/coordinates/1",
+			"/loves/0",
+			"/name/last",
+			"/name.first",
+			"/age",
+			"//a",
+		}},
+		{`{"a":{"b":1},"a":{"c":2}}`, []string{"/a", "/a/b", "/a/c"}},
+		{`[1,"two",{"three":3}]`, []string{"/0", "/2/three", "/01", "/1"}},
+		{`"scalar"`, []string{"", "/a"}},
+	}
+	for i, c := range cases {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			for _, json := range []string{c.json, c.json + "junk"} {
+				results := GetMany(json, c.pointers...)
+				assert(t, len(results) == len(c.pointers))
+				for j, pointer := range c.pointers {
+					expected := Get(json, pointer)
+					if results[j].Raw != expected.Raw || results[j].Type != expected.Type || results[j].Index != expected.Index {
+						t.Fatalf("%q: expected %#v, got %#v", pointer, expected, results[j])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPointerSet(t *testing.T) {
+	var json string
+	var pointers []string
+	json = "{"
+	for i := 0; i < 32; i++ {
+		if i > 0 {
+			json += ","
+		}
+		json += fmt.Sprintf(`"k%d":[%d,{"v":%d}]`, i, i, i)
+		pointers = append(pointers, fmt.Sprintf("/k%d/1/v", i), fmt.Sprintf("/k%d/0", i))
+	}
+	json += "}"
+
+	set, err := ParsePointerSet(pointers...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, set.Len() == len(pointers))
+
+	results := set.GetBytes([]byte(json))
+	for i := 0; i < 32; i++ {
+		assert(t, results[2*i].Int() == int64(i))
+		assert(t, results[2*i+1].Int() == int64(i))
+	}
+
+	_, err = ParsePointerSet("/a", "/b~")
+	assert(t, err != nil)
+}
+
+func TestPointerSetStrict(t *testing.T) {
+	json := `{"foo":["bar","baz"],"":{"":1}}`
+	set := NewPointerSet(
+		MustParsePointer("/foo/x"),
+		must(ParsePointerStrict("/foo/x")),
+		must(ParsePointerStrict("/foo/01")),
+		MustParsePointer("/foo/01"),
+		must(ParsePointerStrict("//")),
+	)
+	results := set.Get(json)
+	assert(t, results[0].Raw == `["bar","baz"]`)
+	assert(t, !results[1].Exists())
+	assert(t, !results[2].Exists())
+	assert(t, results[3].Str == "baz")
+	assert(t, results[4].Int() == 1)
+}
+
+func must(p Pointer, err error) Pointer {
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+func BenchmarkGetManyLoop(b *testing.B) {
+	pointers := []string{"/widget/window/name", "/widget/image/hOffset", "/widget/text/onMouseUp"}
+	for i := 0; i < b.N; i++ {
+		for _, p := range pointers {
+			Get(exampleJSON, p)
+		}
+	}
+}
+
+func BenchmarkPointerSet(b *testing.B) {
+	set, _ := ParsePointerSet("/widget/window/name", "/widget/image/hOffset", "/widget/text/onMouseUp")
+	for i := 0; i < b.N; i++ {
+		set.Get(exampleJSON)
+	}
+}
+
+func BenchmarkGetMany(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		GetMany(exampleJSON, "/widget/window/name", "/widget/image/hOffset", "/widget/text/onMouseUp")
+	}
+}
+
+func BenchmarkGetManyLarge(b *testing.B) {
+	var json strings.Builder
+	json.WriteByte('{')
+	pointers := make([]string, 0, 2000)
+	for i := 0; i < 2000; i++ {
+		if i > 0 {
+			json.WriteByte(',')
+		}
+		fmt.Fprintf(&json, `"k%d":{"v":%d}`, i, i)
+		pointers = append(pointers, fmt.Sprintf("/k%d/v", i))
+	}
+	json.WriteByte('}')
+	doc := json.String()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		GetMany(doc, pointers...)
+	}
+}
@@ -0,0 +1,114 @@
+package jp
+
+// MergePatch applies an RFC 7386 JSON Merge Patch to target and returns the
+// patched document. If the patch is an object, its members are merged into
+// target recursively: members whose value is null are removed, and all other
+// members are added or replaced. If the patch is not an object, it replaces
+// target entirely.
+//
+// Members of target that are not touched by the patch keep their original
+// formatting and order, and new members are appended to the end of their
+// object.
+//
+// This function expects that the json is well-formed, and does not validate.
+// Invalid json will not panic, but it may return back unexpected results.
+func MergePatch[T Stringlike](target, patch T) T {
+	return mergePatch(target, Parse(patch))
+}
+
+func mergePatch[T Stringlike](target T, patch Result[T]) T {
+	if !patch.IsObject() {
+		return T(patch.Raw)
+	}
+	if !Parse(target).IsObject() {
+		target = T("{}")
+	}
+
+	patch.ForEach(func(key, value Result[T]) bool {
+		p := Pointer{tokens: []referenceToken{{key: key.Str, index: -1}}, strict: true}
+		loc, err := locate(target, p)
+		switch {
+		case err != nil:
+		case value.Type == Null:
+			if loc.found {
+				target, _, _ = remove(target, p)
+			}
+		case loc.found:
+			target = splice(target, loc.value.Index, loc.end(), []byte(mergePatch(loc.value.Raw, value)))
+		default:
+			raw := value.Raw
+			if value.IsObject() {
+				// remove any null members
+				raw = mergePatch(T("{}"), value)
+			}
+			target = insert(target, &loc, key.Str, []byte(raw))
+		}
+		return true
+	})
+	return target
+}
+
+// CreateMergePatch returns an RFC 7386 JSON Merge Patch that transforms
+// original into modified. If both documents are objects, the patch contains
+// a member for each member that was added, changed or removed; otherwise, the
+// patch is modified itself.
+//
+// Merge patches cannot set a member to null or express changes to the
+// elements of an array, so changed arrays are replaced entirely and members of
+// modified whose values are null are represented as removals.
+func CreateMergePatch[T Stringlike](original, modified T) T {
+	a, b := Parse(original), Parse(modified)
+	if !a.IsObject() || !b.IsObject() {
+		return T(b.Raw)
+	}
+	return T(createMergePatch(nil, a, b))
+}
+
+func createMergePatch[T Stringlike](buf []byte, a, b Result[T]) []byte {
+	am, bm := a.Map(), b.Map()
+
+	buf = append(buf, '{')
+	first := true
+	member := func(key string) {
+		if !first {
+			buf = append(buf, ',')
+		}
+		first = false
+		buf = appendQuoted(buf, key)
+		buf = append(buf, ':')
+	}
+
+	seen := map[string]bool{}
+	for it := a.Range(); it.Next(); {
+		key := it.Key().Str
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		av := am[key]
+		bv, ok := bm[key]
+		switch {
+		case !ok:
+			member(key)
+			buf = append(buf, "null"...)
+		case equal(av, bv):
+		case av.IsObject() && bv.IsObject():
+			member(key)
+			buf = createMergePatch(buf, av, bv)
+		default:
+			member(key)
+			buf = append(buf, bv.Raw...)
+		}
+	}
+	for it := b.Range(); it.Next(); {
+		key := it.Key().Str
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		member(key)
+		buf = append(buf, bm[key].Raw...)
+	}
+	return append(buf, '}')
+}
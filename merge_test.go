@@ -0,0 +1,73 @@
+package jp
+
+import (
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	// examples from RFC 7386, appendix A
+	cases := []struct {
+		target   string
+		patch    string
+		expected string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		{
+			"{\n  \"title\": \"Goodbye!\",\n  \"author\": {\"givenName\": \"John\", \"familyName\": \"Doe\"},\n  \"tags\": [\"example\", \"sample\"]\n}",
+			`{"title":"Hello!","author":{"familyName":null},"tags":["example"],"phoneNumber":"+01-123-456-7890"}`,
+			"{\n  \"title\": \"Hello!\",\n  \"author\": {\"givenName\": \"John\"},\n  \"tags\": [\"example\"],\n  \"phoneNumber\": \"+01-123-456-7890\"\n}",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.target+c.patch, func(t *testing.T) {
+			actual := MergePatch(c.target, c.patch)
+			if actual != c.expected {
+				t.Fatalf("expected %q, got %q", c.expected, actual)
+			}
+
+			actualBytes := MergePatch([]byte(c.target), []byte(c.patch))
+			if string(actualBytes) != c.expected {
+				t.Fatalf("expected %q, got %q", c.expected, actualBytes)
+			}
+		})
+	}
+}
+
+func TestCreateMergePatch(t *testing.T) {
+	cases := []struct {
+		original string
+		modified string
+		expected string
+	}{
+		{`{"a":"b"}`, `{"a":"b"}`, `{}`},
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b","b":"c"}`, `{"b":"c"}`, `{"a":null}`},
+		{`{"a":"b"}`, `{"a":"b","c":[1, 2]}`, `{"c":[1, 2]}`},
+		{`{"a":{"b":"c","d":"e"}}`, `{"a":{"b":"d","d":"e"}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[1,2]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`{"a":1}`, `[1]`, `[1]`},
+	}
+	for _, c := range cases {
+		t.Run(c.original+c.modified, func(t *testing.T) {
+			actual := CreateMergePatch(c.original, c.modified)
+			if actual != c.expected {
+				t.Fatalf("expected %q, got %q", c.expected, actual)
+			}
+			assert(t, equal(Parse(MergePatch(c.original, actual)), Parse(c.modified)))
+		})
+	}
+}
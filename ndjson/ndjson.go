@@ -0,0 +1,280 @@
+// Package ndjson reads newline-delimited JSON (also known as JSON Lines) one
+// record at a time.
+package ndjson
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+
+	"github.com/pgavlin/jp/v3"
+)
+
+// RecordError describes a malformed record.
+type RecordError struct {
+	// Line is the 1-based line number of the record.
+	Line int
+	// Err is the underlying error. For records that are not valid JSON, Err
+	// is a *jp.SyntaxError whose offsets are relative to the start of the
+	// record.
+	Err error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("ndjson: line %d: %v", e.Line, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// Options controls how a Reader processes records.
+type Options struct {
+	// Pointers, if non-nil, is resolved against each record. The results are
+	// available from the Reader's Values method.
+	Pointers *jp.PointerSet
+	// Workers is the number of goroutines that validate records and resolve
+	// pointers. If Workers is greater than one, records are processed in
+	// parallel, but are still returned in the order in which they were read.
+	// If Workers is negative, runtime.GOMAXPROCS(0) workers are used.
+	Workers int
+}
+
+// Reader reads newline-delimited JSON records from an io.Reader. Blank lines
+// are skipped.
+//
+// If a record is malformed, Next returns false and Err returns a
+// *RecordError that identifies its line. Calling Next again continues with
+// the following record.
+type Reader struct {
+	lines   lineReader
+	options Options
+
+	line   int
+	record jp.Result[[]byte]
+	values []jp.Result[[]byte]
+	err    error
+	done   bool
+
+	// ordered holds the records that are being processed by the worker pool,
+	// in the order in which they were read.
+	ordered chan *record
+	stop    chan struct{}
+	close   sync.Once
+}
+
+// record is a single record being processed by the worker pool.
+type record struct {
+	line   int
+	data   []byte
+	result jp.Result[[]byte]
+	values []jp.Result[[]byte]
+	err    error
+	// fatal is true if err is an error returned by the underlying reader.
+	fatal bool
+	done  chan struct{}
+}
+
+// NewReader returns a Reader that reads records from r.
+func NewReader(r io.Reader) *Reader {
+	return NewReaderWithOptions(r, Options{})
+}
+
+// NewReaderWithOptions returns a Reader that reads records from r using the
+// given options. If the options specify more than one worker, the Reader
+// must be closed with Close if it is not read until Next returns false.
+func NewReaderWithOptions(r io.Reader, options Options) *Reader {
+	if options.Workers < 0 {
+		options.Workers = runtime.GOMAXPROCS(0)
+	}
+	reader := &Reader{lines: lineReader{r: bufio.NewReader(r)}, options: options}
+	if options.Workers > 1 {
+		reader.start()
+	}
+	return reader
+}
+
+// Next advances the reader to the next record. It returns false at the end
+// of the input or if an error occurs. In the latter case, Err returns the
+// error. A *RecordError does not end the input: calling Next again advances
+// to the record that follows the malformed one.
+func (r *Reader) Next() bool {
+	if r.done {
+		return false
+	}
+	r.record, r.values, r.err = jp.Result[[]byte]{}, nil, nil
+
+	var rec *record
+	if r.ordered != nil {
+		var ok bool
+		if rec, ok = <-r.ordered; !ok {
+			r.done = true
+			return false
+		}
+		<-rec.done
+	} else {
+		line, data, err := r.lines.next()
+		switch {
+		case err == io.EOF:
+			r.done = true
+			return false
+		case err != nil:
+			rec = &record{err: err, fatal: true}
+		default:
+			rec = &record{line: line, data: data}
+			r.process(rec)
+		}
+	}
+
+	r.line = rec.line
+	switch {
+	case rec.fatal:
+		r.done, r.err = true, rec.err
+		return false
+	case rec.err != nil:
+		r.err = rec.err
+		return false
+	default:
+		r.record, r.values = rec.result, rec.values
+		return true
+	}
+}
+
+// Record returns the current record. If the reader does not use a worker
+// pool, the record's Raw field refers to the reader's buffer, and is only
+// valid until the next call to Next.
+func (r *Reader) Record() jp.Result[[]byte] {
+	return r.record
+}
+
+// Values returns the results of resolving the reader's pointers against the
+// current record, in the same order as the pointers. It returns nil if the
+// reader has no pointers.
+func (r *Reader) Values() []jp.Result[[]byte] {
+	return r.values
+}
+
+// Line returns the 1-based line number of the current record.
+func (r *Reader) Line() int {
+	return r.line
+}
+
+// Err returns the error, if any, that caused the last call to Next to return
+// false. Malformed records are reported as *RecordError.
+func (r *Reader) Err() error {
+	return r.err
+}
+
+// Close stops the reader's workers. It does not close the underlying reader.
+func (r *Reader) Close() error {
+	if r.stop != nil {
+		r.close.Do(func() { close(r.stop) })
+	}
+	r.done = true
+	return nil
+}
+
+// process validates a record and resolves the reader's pointers against it.
+func (r *Reader) process(rec *record) {
+	if err := jp.Validate(rec.data); err != nil {
+		rec.err = &RecordError{Line: rec.line, Err: err}
+		return
+	}
+	rec.result = jp.Parse(rec.data)
+	if r.options.Pointers != nil {
+		rec.values = r.options.Pointers.GetBytes(rec.data)
+	}
+}
+
+// start starts the worker pool.
+func (r *Reader) start() {
+	workers := r.options.Workers
+	jobs := make(chan *record)
+	r.ordered = make(chan *record, 2*workers)
+	r.stop = make(chan struct{})
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			for rec := range jobs {
+				r.process(rec)
+				close(rec.done)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(r.ordered)
+		defer close(jobs)
+		for {
+			line, data, err := r.lines.next()
+			if err == io.EOF {
+				return
+			}
+
+			rec := &record{line: line, done: make(chan struct{})}
+			if err != nil {
+				rec.err, rec.fatal = err, true
+				close(rec.done)
+			} else {
+				rec.data = append([]byte(nil), data...)
+				select {
+				case jobs <- rec:
+				case <-r.stop:
+					return
+				}
+			}
+
+			select {
+			case r.ordered <- rec:
+			case <-r.stop:
+				return
+			}
+			if rec.fatal {
+				return
+			}
+		}
+	}()
+}
+
+// lineReader splits its input into non-blank lines.
+type lineReader struct {
+	r    *bufio.Reader
+	line int
+	buf  []byte
+}
+
+// next returns the next non-blank line and its line number. The line is only
+// valid until the next call to next.
+func (l *lineReader) next() (int, []byte, error) {
+	for {
+		l.line++
+		l.buf = l.buf[:0]
+
+		var data []byte
+		var err error
+		for {
+			data, err = l.r.ReadSlice('\n')
+			if err != bufio.ErrBufferFull {
+				break
+			}
+			l.buf = append(l.buf, data...)
+		}
+		if len(l.buf) != 0 {
+			l.buf = append(l.buf, data...)
+			data = l.buf
+		}
+		if err != nil && (err != io.EOF || len(data) == 0) {
+			return l.line, nil, err
+		}
+
+		if data = bytes.TrimSpace(data); len(data) != 0 {
+			return l.line, data, nil
+		}
+		if err == io.EOF {
+			return l.line, nil, err
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package ndjson
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/pgavlin/jp/v3"
+)
+
+func TestReader(t *testing.T) {
+	input := "{\"a\":1}\n\n  [1,2]  \r\n\"three\"\n{\"a\":{\"b\":4}}"
+	expected := []struct {
+		line int
+		raw  string
+	}{
+		{1, `{"a":1}`},
+		{3, `[1,2]`},
+		{4, `"three"`},
+		{5, `{"a":{"b":4}}`},
+	}
+
+	for _, r := range []io.Reader{strings.NewReader(input), iotest.OneByteReader(strings.NewReader(input))} {
+		reader := NewReader(r)
+		i := 0
+		for reader.Next() {
+			if reader.Line() != expected[i].line || string(reader.Record().Raw) != expected[i].raw {
+				t.Fatalf("record %v: expected %v:%s, got %v:%s", i, expected[i].line, expected[i].raw, reader.Line(), reader.Record().Raw)
+			}
+			assert(t, reader.Values() == nil)
+			i++
+		}
+		if reader.Err() != nil {
+			t.Fatal(reader.Err())
+		}
+		assert(t, i == len(expected))
+		assert(t, !reader.Next())
+	}
+}
+
+func TestReaderErrors(t *testing.T) {
+	reader := NewReader(strings.NewReader("{\"a\":1}\n{\"a\":\n[3]\n"))
+	assert(t, reader.Next())
+	assert(t, !reader.Next())
+
+	var rerr *RecordError
+	if !errors.As(reader.Err(), &rerr) {
+		t.Fatalf("expected a *RecordError, got %v", reader.Err())
+	}
+	assert(t, rerr.Line == 2)
+	assert(t, reader.Line() == 2)
+
+	// reading continues after a malformed record
+	assert(t, reader.Next())
+	assert(t, string(reader.Record().Raw) == "[3]")
+	assert(t, reader.Err() == nil)
+	assert(t, !reader.Next())
+	assert(t, reader.Err() == nil)
+
+	readErr := errors.New("boom")
+	reader = NewReader(io.MultiReader(strings.NewReader("1\n2\n"), iotest.ErrReader(readErr)))
+	assert(t, reader.Next() && reader.Next())
+	assert(t, !reader.Next())
+	assert(t, reader.Err() == readErr)
+	assert(t, !reader.Next())
+}
+
+func TestReaderPointers(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < 1000; i++ {
+		if i%100 == 99 {
+			b.WriteString("{bad\n")
+			continue
+		}
+		fmt.Fprintf(&b, `{"id":%d,"user":{"name":"user %d"}}`+"\n", i, i)
+	}
+
+	set, err := jp.ParsePointerSet("/user/name", "/id", "/missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, workers := range []int{0, 1, 4, -1} {
+		t.Run(fmt.Sprint(workers), func(t *testing.T) {
+			reader := NewReaderWithOptions(strings.NewReader(b.String()), Options{Pointers: set, Workers: workers})
+			defer reader.Close()
+
+			records, errs := 0, 0
+			for {
+				if !reader.Next() {
+					var rerr *RecordError
+					if !errors.As(reader.Err(), &rerr) {
+						break
+					}
+					assert(t, rerr.Line%100 == 0)
+					errs++
+					continue
+				}
+
+				id := records + records/99
+				values := reader.Values()
+				assert(t, len(values) == 3)
+				assert(t, values[0].String() == fmt.Sprintf("user %d", id))
+				assert(t, values[1].Int() == int64(id))
+				assert(t, !values[2].Exists())
+				assert(t, reader.Line() == id+1)
+				records++
+			}
+			assert(t, reader.Err() == nil)
+			assert(t, records == 990)
+			assert(t, errs == 10)
+		})
+	}
+}
+
+func TestReaderClose(t *testing.T) {
+	reader := NewReaderWithOptions(strings.NewReader(strings.Repeat("[1]\n", 10000)), Options{Workers: 4})
+	assert(t, reader.Next())
+	assert(t, reader.Close() == nil)
+	assert(t, !reader.Next())
+}
+
+func assert(t testing.TB, cond bool) {
+	t.Helper()
+	if !cond {
+		t.Fatal("assert failed")
+	}
+}
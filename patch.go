@@ -0,0 +1,163 @@
+package jp
+
+import (
+	"errors"
+	"fmt"
+)
+
+// PatchError describes an RFC 6902 patch operation that could not be applied.
+type PatchError struct {
+	// Index is the position of the failing operation within the patch.
+	Index int
+	// Op is the name of the failing operation, e.g. "add" or "test".
+	Op string
+	// Err is the underlying error. If the operation's path or from pointer
+	// did not resolve, Err is a *PointerError.
+	Err error
+}
+
+func (e *PatchError) Error() string {
+	if e.Op == "" {
+		return fmt.Sprintf("applying patch operation %d: %v", e.Index, e.Err)
+	}
+	return fmt.Sprintf("applying patch operation %d (%q): %v", e.Index, e.Op, e.Err)
+}
+
+func (e *PatchError) Unwrap() error {
+	return e.Err
+}
+
+// errTestFailed is returned when the value of a "test" operation does not
+// match the document.
+var errTestFailed = errors.New("test failed: value does not match")
+
+// ApplyPatch applies an RFC 6902 JSON Patch to doc and returns the patched
+// document. The patch must be a JSON array of operations, each of which is one
+// of "add", "remove", "replace", "move", "copy" or "test". Pointers are
+// interpreted as they would be by GetStrict, and the "-" token refers to the
+// end of an array.
+//
+// Patches are applied atomically: if any operation fails, doc is returned
+// unchanged along with a *PatchError that identifies the failing operation.
+// The parts of the document that are not touched by the patch keep their
+// original formatting.
+func ApplyPatch[T Stringlike](doc, patch T) (T, error) {
+	if err := Validate(patch); err != nil {
+		return doc, fmt.Errorf("invalid JSON patch: %w", err)
+	}
+	ops := Parse(patch)
+	if !ops.IsArray() {
+		return doc, errors.New("JSON patch must be an array")
+	}
+
+	result, index := doc, 0
+	for it := ops.Range(); it.Next(); index++ {
+		op := it.Value()
+		name := op.Get("op")
+
+		var err error
+		result, err = applyOperation(result, op, name)
+		if err != nil {
+			return doc, &PatchError{Index: index, Op: name.Str, Err: err}
+		}
+	}
+	return result, nil
+}
+
+// applyOperation applies a single patch operation to doc.
+func applyOperation[T Stringlike, U Stringlike](doc T, op, name Result[U]) (T, error) {
+	if !op.IsObject() {
+		return doc, errors.New("operation must be an object")
+	}
+	if name.Type != String {
+		return doc, errors.New(`missing "op" member`)
+	}
+	path, p, err := patchPointer(op, "path")
+	if err != nil {
+		return doc, err
+	}
+
+	switch name.Str {
+	case "add":
+		value, err := patchValue(op)
+		if err != nil {
+			return doc, err
+		}
+		result, perr := add(doc, p, value)
+		if perr != nil {
+			return doc, withPointer(perr, path)
+		}
+		return result, nil
+	case "remove":
+		result, _, err := remove(doc, p)
+		return result, withPointer(err, path)
+	case "replace":
+		value, err := patchValue(op)
+		if err != nil {
+			return doc, err
+		}
+		loc, err := locateExisting(doc, p)
+		if err != nil {
+			return doc, withPointer(err, path)
+		}
+		return splice(doc, loc.value.Index, loc.end(), value), nil
+	case "move":
+		fromPath, from, err := patchPointer(op, "from")
+		if err != nil {
+			return doc, err
+		}
+		return move(doc, from, p, fromPath, path)
+	case "copy":
+		fromPath, from, err := patchPointer(op, "from")
+		if err != nil {
+			return doc, err
+		}
+		loc, err := locateExisting(doc, from)
+		if err != nil {
+			return doc, withPointer(err, fromPath)
+		}
+		result, perr := add(doc, p, []byte(loc.value.Raw))
+		if perr != nil {
+			return doc, withPointer(perr, path)
+		}
+		return result, nil
+	case "test":
+		value, err := patchValue(op)
+		if err != nil {
+			return doc, err
+		}
+		loc, err := locateExisting(doc, p)
+		if err != nil {
+			return doc, withPointer(err, path)
+		}
+		if !equal(loc.value, Parse(value)) {
+			return doc, errTestFailed
+		}
+		return doc, nil
+	default:
+		return doc, fmt.Errorf("unknown operation %q", name.Str)
+	}
+}
+
+// patchPointer returns the pointer stored in the named member of a patch
+// operation.
+func patchPointer[T Stringlike](op Result[T], member string) (string, Pointer, error) {
+	pointer := op.Get(member)
+	if pointer.Type != String {
+		return "", Pointer{}, fmt.Errorf("missing %q member", member)
+	}
+	p, err := ParsePointerStrict(pointer.Str)
+	if err != nil {
+		return "", Pointer{}, err
+	}
+	return pointer.Str, p, nil
+}
+
+// patchValue returns the raw value of a patch operation.
+func patchValue[T Stringlike](op Result[T]) ([]byte, error) {
+	value := op.Get("value")
+	if !value.Exists() {
+		return nil, errors.New(`missing "value" member`)
+	}
+	return []byte(value.Raw), nil
+}
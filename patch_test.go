@@ -0,0 +1,110 @@
+package jp
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestApplyPatch(t *testing.T) {
+	// examples from RFC 6902, appendix A
+	cases := []struct {
+		doc      string
+		patch    string
+		expected string
+	}{
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"foo":"bar","baz":"qux"}`},
+		{`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{`{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{
+			`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			`[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`,
+		},
+		{`{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{
+			`{"baz":"qux","foo":["a",2,"c"]}`,
+			`[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`,
+			`{"baz":"qux","foo":["a",2,"c"]}`,
+		},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"foo":"bar","child":{"grandchild":{}}}`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux","xyz":123}]`, `{"foo":"bar","baz":"qux"}`},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`},
+		{`{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":10}]`, `{"/":9,"~1":10}`},
+		{`{"foo":null}`, `[{"op":"add","path":"/bar","value":null}]`, `{"foo":null,"bar":null}`},
+		{`{"a":{"b":1}}`, `[{"op":"copy","from":"/a","path":"/c"}]`, `{"a":{"b":1},"c":{"b":1}}`},
+		{`{"a":1}`, `[{"op":"replace","path":"","value":[1,2]}]`, `[1,2]`},
+		{`{"a":{"x":1,"y":[1.0,"A"]}}`, `[{"op":"test","path":"/a","value":{"y":[1,"A"],"x":1}}]`, `{"a":{"x":1,"y":[1.0,"A"]}}`},
+		{
+			"{\n  \"a\": 1,\n  \"b\": 2\n}",
+			`[{"op":"remove","path":"/a"},{"op":"add","path":"/c","value":3}]`,
+			"{\n  \"b\": 2,\n  \"c\": 3\n}",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.patch, func(t *testing.T) {
+			actual, err := ApplyPatch(c.doc, c.patch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != c.expected {
+				t.Fatalf("expected %q, got %q", c.expected, actual)
+			}
+
+			actualBytes, err := ApplyPatch([]byte(c.doc), []byte(c.patch))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(actualBytes) != c.expected {
+				t.Fatalf("expected %q, got %q", c.expected, actualBytes)
+			}
+		})
+	}
+}
+
+func TestApplyPatchErrors(t *testing.T) {
+	cases := []struct {
+		doc     string
+		patch   string
+		index   int
+		op      string
+		pointer bool
+	}{
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"add","path":"/x","value":1},{"op":"test","path":"/baz","value":"bar"}]`, 1, "test", false},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, 0, "add", true},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/2","value":1}]`, 0, "add", true},
+		{`{"foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, 0, "remove", true},
+		{`{"foo":"bar"}`, `[{"op":"replace","path":"/baz","value":1}]`, 0, "replace", true},
+		{`{"foo":"bar"}`, `[{"op":"move","from":"/baz","path":"/qux"}]`, 0, "move", true},
+		{`{"foo":{"a":1}}`, `[{"op":"move","from":"/foo","path":"/foo/b"}]`, 0, "move", false},
+		{`{"foo":"bar"}`, `[{"op":"copy","from":"/baz","path":"/qux"}]`, 0, "copy", true},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz"}]`, 0, "add", false},
+		{`{"foo":"bar"}`, `[{"op":"remove"}]`, 0, "remove", false},
+		{`{"foo":"bar"}`, `[{"op":"frob","path":"/foo"}]`, 0, "frob", false},
+		{`{"foo":"bar"}`, `[{"path":"/foo"}]`, 0, "", false},
+		{`{"foo":"bar"}`, `[1]`, 0, "", false},
+	}
+	for _, c := range cases {
+		t.Run(c.patch, func(t *testing.T) {
+			actual, err := ApplyPatch(c.doc, c.patch)
+			assert(t, actual == c.doc)
+
+			var perr *PatchError
+			if !errors.As(err, &perr) {
+				t.Fatalf("expected a *PatchError, got %v", err)
+			}
+			assert(t, perr.Index == c.index)
+			assert(t, perr.Op == c.op)
+
+			var pointerErr *PointerError
+			assert(t, errors.As(err, &pointerErr) == c.pointer)
+		})
+	}
+
+	for _, patch := range []string{`{"op":"add"}`, `[`, ``} {
+		actual, err := ApplyPatch(`{}`, patch)
+		assert(t, err != nil)
+		assert(t, actual == `{}`)
+	}
+}
@@ -0,0 +1,379 @@
+package jp
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Pointer is a precompiled JSON pointer. Compiling a pointer splits and
+// unescapes its reference tokens once, which avoids repeating that work for
+// every lookup when the same pointer is evaluated against many documents.
+//
+// The zero value of Pointer refers to the whole document.
+type Pointer struct {
+	tokens []referenceToken
+	strict bool
+}
+
+// referenceToken is a single unescaped reference token of a pointer.
+type referenceToken struct {
+	// key is the unescaped token, used when indexing into an object.
+	key string
+	// index is the token interpreted as an array index, or -1 if the token is
+	// not a valid array index.
+	index int
+}
+
+// PointerSyntaxError describes a malformed JSON pointer.
+type PointerSyntaxError struct {
+	// Pointer is the malformed pointer.
+	Pointer string
+	// Offset is the byte offset of the error within the pointer.
+	Offset int
+
+	msg string
+}
+
+func (e *PointerSyntaxError) Error() string {
+	return fmt.Sprintf("invalid JSON pointer %q: %s at offset %d", e.Pointer, e.msg, e.Offset)
+}
+
+// PointerErrorKind identifies the reason that a pointer did not resolve.
+type PointerErrorKind int
+
+const (
+	// KeyNotFound indicates that an object has no member with the key named
+	// by a reference token.
+	KeyNotFound PointerErrorKind = iota + 1
+	// NotContainer indicates that a reference token was applied to a value
+	// that is neither an object nor an array.
+	NotContainer
+	// IndexOutOfRange indicates that a reference token names an element past
+	// the end of an array.
+	IndexOutOfRange
+	// InvalidIndex indicates that a reference token applied to an array is
+	// not an array index.
+	InvalidIndex
+	// MalformedJSON indicates that the document is malformed or truncated.
+	MalformedJSON
+)
+
+// String returns a description of the kind of error.
+func (k PointerErrorKind) String() string {
+	switch k {
+	default:
+		return ""
+	case KeyNotFound:
+		return "key not found"
+	case NotContainer:
+		return "value is not an object or array"
+	case IndexOutOfRange:
+		return "array index out of range"
+	case InvalidIndex:
+		return "invalid array index"
+	case MalformedJSON:
+		return "malformed JSON"
+	}
+}
+
+// PointerError describes why a pointer did not resolve.
+type PointerError struct {
+	// Kind is the kind of error.
+	Kind PointerErrorKind
+	// Pointer is the pointer that did not resolve.
+	Pointer string
+	// Token is the unescaped reference token that could not be resolved.
+	Token string
+	// TokenIndex is the position of Token within the pointer. A TokenIndex of
+	// 0 refers to the first reference token.
+	TokenIndex int
+	// Offset is the byte offset in the document at which resolution stopped.
+	Offset int
+	// Type is the type of the value that a NotContainer error attempted to
+	// index into.
+	Type Type
+
+	// msg replaces the description of the error's kind, if set.
+	msg string
+}
+
+func (e *PointerError) Error() string {
+	switch {
+	case e.msg != "":
+		return fmt.Sprintf("resolving JSON pointer %q: %s at offset %d", e.Pointer, e.msg, e.Offset)
+	case e.Kind == MalformedJSON:
+		return fmt.Sprintf("resolving JSON pointer %q: malformed JSON at offset %d", e.Pointer, e.Offset)
+	case e.Kind == NotContainer:
+		return fmt.Sprintf("resolving JSON pointer %q: cannot apply reference token %d (%q) to %v value at offset %d",
+			e.Pointer, e.TokenIndex, e.Token, e.Type, e.Offset)
+	default:
+		return fmt.Sprintf("resolving JSON pointer %q: %v: reference token %d (%q) at offset %d",
+			e.Pointer, e.Kind, e.TokenIndex, e.Token, e.Offset)
+	}
+}
+
+// ParsePointer compiles a JSON pointer for repeated use.
+//
+// The pointer is interpreted exactly as it would be by Get, with one
+// exception: malformed escape sequences (a '~' that is not followed by '0' or
+// '1') are reported as errors rather than guessed at.
+func ParsePointer(pointer string) (Pointer, error) {
+	tokens, err := appendReferenceTokens(nil, pointer)
+	if err != nil {
+		return Pointer{}, err
+	}
+	return Pointer{tokens: tokens}, nil
+}
+
+// ParsePointerStrict compiles a JSON pointer that follows RFC 6901 exactly.
+// Unlike pointers accepted by Get and ParsePointer:
+//
+//   - a non-empty pointer must begin with '/'
+//   - every '/' separates two reference tokens, so empty tokens address
+//     members with the empty key (e.g. "/" refers to the member named "")
+//   - array indices must not have leading zeros, and tokens that are not
+//     array indices (including "-") never match an array element
+//   - escape sequences other than "~0" and "~1" are errors
+func ParsePointerStrict(pointer string) (Pointer, error) {
+	if pointer == "" {
+		return Pointer{strict: true}, nil
+	}
+	if pointer[0] != '/' {
+		return Pointer{}, &PointerSyntaxError{Pointer: pointer, msg: "pointer must begin with '/'"}
+	}
+
+	var tokens []referenceToken
+	for i := 1; ; i++ {
+		start, escaped := i, false
+		for ; i < len(pointer) && pointer[i] != '/'; i++ {
+			if pointer[i] == '~' {
+				if i+1 == len(pointer) || (pointer[i+1] != '0' && pointer[i+1] != '1') {
+					if i+1 == len(pointer) || pointer[i+1] == '/' {
+						return Pointer{}, &PointerSyntaxError{Pointer: pointer, Offset: i, msg: "incomplete escape sequence"}
+					}
+					return Pointer{}, &PointerSyntaxError{Pointer: pointer, Offset: i, msg: "invalid escape sequence " + strconv.Quote(pointer[i:i+2])}
+				}
+				escaped = true
+			}
+		}
+
+		ref := pointer[start:i]
+		if escaped {
+			// RFC 6901 requires that "~1" is transformed before "~0"
+			ref = strings.ReplaceAll(strings.ReplaceAll(ref, "~1", "/"), "~0", "~")
+		}
+		tokens = append(tokens, referenceToken{key: ref, index: strictArrayIndex(ref)})
+
+		if i == len(pointer) {
+			return Pointer{tokens: tokens, strict: true}, nil
+		}
+	}
+}
+
+// MustParsePointer is like ParsePointer, but panics if the pointer is
+// malformed.
+func MustParsePointer(pointer string) Pointer {
+	p, err := ParsePointer(pointer)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Get searches json for the value referred to by the pointer.
+//
+// This function expects that the json is well-formed, and does not validate.
+// Invalid json will not panic, but it may return back unexpected results.
+func (p Pointer) Get(json string) Result[string] {
+	return getPointer(json, p)
+}
+
+// GetBytes searches json for the value referred to by the pointer.
+//
+// This function expects that the json is well-formed, and does not validate.
+// Invalid json will not panic, but it may return back unexpected results.
+func (p Pointer) GetBytes(json []byte) Result[[]byte] {
+	return getPointer(json, p)
+}
+
+// Lookup searches json for the value referred to by the pointer. If the
+// pointer does not resolve, the returned error is a *PointerError that
+// describes why. See the Lookup function for details.
+func (p Pointer) Lookup(json string) (Result[string], error) {
+	result, err := lookupPointer(json, p)
+	if err != nil {
+		err.Pointer = p.String()
+		return result, err
+	}
+	return result, nil
+}
+
+// LookupBytes searches json for the value referred to by the pointer. If the
+// pointer does not resolve, the returned error is a *PointerError that
+// describes why. See the Lookup function for details.
+func (p Pointer) LookupBytes(json []byte) (Result[[]byte], error) {
+	result, err := lookupPointer(json, p)
+	if err != nil {
+		err.Pointer = p.String()
+		return result, err
+	}
+	return result, nil
+}
+
+// Len returns the number of reference tokens in the pointer.
+func (p Pointer) Len() int {
+	return len(p.tokens)
+}
+
+// Token returns the i'th unescaped reference token of the pointer.
+func (p Pointer) Token(i int) string {
+	return p.tokens[i].key
+}
+
+// Strict returns true if the pointer was compiled by ParsePointerStrict.
+func (p Pointer) Strict() bool {
+	return p.strict
+}
+
+// String returns the pointer in RFC 6901 syntax.
+func (p Pointer) String() string {
+	var b strings.Builder
+	for _, t := range p.tokens {
+		b.WriteByte('/')
+		writeEscapedToken(&b, t.key)
+	}
+	return b.String()
+}
+
+// writeEscapedToken writes a reference token to b, escaping '~' and '/'.
+func writeEscapedToken(b *strings.Builder, token string) {
+	for i := 0; i < len(token); i++ {
+		switch c := token[i]; c {
+		case '~':
+			b.WriteString("~0")
+		case '/':
+			b.WriteString("~1")
+		default:
+			b.WriteByte(c)
+		}
+	}
+}
+
+// appendReferenceTokens splits pointer into its reference tokens and appends
+// them to dst. Leading slashes are ignored and runs of slashes are treated as
+// a single separator. Malformed escapes are passed through as well as
+// possible; the first one encountered is reported in the returned error.
+func appendReferenceTokens(dst []referenceToken, pointer string) ([]referenceToken, error) {
+	var err error
+
+	i := 0
+	for i < len(pointer) && pointer[i] == '/' {
+		i++
+	}
+	for i < len(pointer) {
+		// find the end of the pointer or the next '/'
+		start, escaped := i, false
+		for ; i < len(pointer) && pointer[i] != '/'; i++ {
+			if pointer[i] == '~' {
+				escaped = true
+			}
+		}
+		ref := pointer[start:i]
+		for i < len(pointer) && pointer[i] == '/' {
+			i++
+		}
+
+		if escaped {
+			var b strings.Builder
+			b.Grow(len(ref))
+			for j := 0; j < len(ref); j++ {
+				c := ref[j]
+				if c == '~' {
+					j++
+					if j == len(ref) {
+						if err == nil {
+							err = &PointerSyntaxError{Pointer: pointer, Offset: start + j - 1, msg: "incomplete escape sequence"}
+						}
+						b.WriteByte(c)
+						break
+					}
+
+					c = ref[j]
+					if c == '0' {
+						c = '~'
+					} else if c == '1' {
+						c = '/'
+					} else if err == nil {
+						err = &PointerSyntaxError{Pointer: pointer, Offset: start + j - 1, msg: "invalid escape sequence " + strconv.Quote(ref[j-1:j+1])}
+					}
+				}
+				b.WriteByte(c)
+			}
+			ref = b.String()
+		}
+
+		dst = append(dst, referenceToken{key: ref, index: arrayIndex(ref)})
+	}
+	return dst, err
+}
+
+// arrayIndex interprets a reference token as an array index. It returns -1 if
+// the token is not a base-10 integer or if the index does not fit in an int.
+func arrayIndex(ref string) int {
+	if ref == "" {
+		return -1
+	}
+
+	index := 0
+	for i := 0; i < len(ref); i++ {
+		c := ref[i]
+		if c < '0' || c > '9' {
+			return -1
+		}
+		d := int(c - '0')
+		if index > (math.MaxInt-d)/10 {
+			return -1
+		}
+		index = index*10 + d
+	}
+	return index
+}
+
+// strictArrayIndex interprets a reference token as an RFC 6901 array index.
+// It returns -1 if the token is not a base-10 integer without leading zeros
+// or if the index does not fit in an int.
+func strictArrayIndex(ref string) int {
+	if ref == "" || (ref[0] == '0' && len(ref) > 1) {
+		return -1
+	}
+
+	index := 0
+	for i := 0; i < len(ref); i++ {
+		c := ref[i]
+		if c < '0' || c > '9' {
+			return -1
+		}
+		d := int(c - '0')
+		if index > (math.MaxInt-d)/10 {
+			return -1
+		}
+		index = index*10 + d
+	}
+	return index
+}
+
+// isPrefixOf returns true if p is a prefix of other, i.e. if other refers to
+// the value referred to by p or to one of its descendants.
+func (p Pointer) isPrefixOf(other Pointer) bool {
+	if len(p.tokens) > len(other.tokens) {
+		return false
+	}
+	for i, t := range p.tokens {
+		if other.tokens[i].key != t.key {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,270 @@
+package jp
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestParsePointer(t *testing.T) {
+	pointers := []string{
+		"",
+		"/",
+		"/age",
+		"age",
+		"//name///last",
+		"/name/last/",
+		"/loggy/programmers/1/firstName",
+		"/loggy/programmers/x",
+		"/items/3/points/1/0",
+		"/arr/3/hello",
+		"/noop/what is a wren?",
+		"/missing",
+	}
+	for _, pointer := range pointers {
+		t.Run(pointer, func(t *testing.T) {
+			p, err := ParsePointer(pointer)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected := Get(basicJSON, pointer)
+			actual := p.Get(basicJSON)
+			if actual != expected {
+				t.Fatalf("expected %#v, got %#v", expected, actual)
+			}
+
+			expectedBytes := Get([]byte(basicJSON), pointer)
+			actualBytes := p.GetBytes([]byte(basicJSON))
+			if string(actualBytes.Raw) != string(expectedBytes.Raw) || actualBytes.Index != expectedBytes.Index {
+				t.Fatalf("expected %#v, got %#v", expectedBytes, actualBytes)
+			}
+
+			nested := Parse(basicJSON).GetPointer(p)
+			if nested.Raw != expected.Raw || expected.Exists() && nested.Index != expected.Index {
+				t.Fatalf("expected %#v, got %#v", expected, nested)
+			}
+		})
+	}
+}
+
+func TestParsePointerEscapes(t *testing.T) {
+	json := `{"a/b":{"c~d":1}}`
+	p, err := ParsePointer("/a~1b/c~0d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, p.Len() == 2)
+	assert(t, p.Token(0) == "a/b")
+	assert(t, p.Token(1) == "c~d")
+	assert(t, p.String() == "/a~1b/c~0d")
+	assert(t, p.Get(json).Int() == 1)
+
+	for _, pointer := range []string{"/a~", "/a~2", "/~x/b"} {
+		_, err := ParsePointer(pointer)
+		if _, ok := err.(*PointerSyntaxError); !ok {
+			t.Fatalf("expected a syntax error for %q, got %v", pointer, err)
+		}
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		Get(exampleJSON, "/widget/text/onMouseUp")
+	}
+}
+
+func BenchmarkPointerGet(b *testing.B) {
+	p := MustParsePointer("/widget/text/onMouseUp")
+	for i := 0; i < b.N; i++ {
+		p.Get(exampleJSON)
+	}
+}
+
+func TestGetStrict(t *testing.T) {
+	// the example document from RFC 6901, section 5
+	json := `{
+		"foo": ["bar", "baz"],
+		"": 0,
+		"a/b": 1,
+		"c%d": 2,
+		"e^f": 3,
+		"g|h": 4,
+		"i\\j": 5,
+		"k\"l": 6,
+		" ": 7,
+		"m~n": 8,
+		"o": {"": {"": 9}}
+	}`
+
+	cases := []struct {
+		pointer  string
+		expected string
+	}{
+		{"/foo", `["bar", "baz"]`},
+		{"/foo/0", `"bar"`},
+		{"/", "0"},
+		{"/a~1b", "1"},
+		{"/c%d", "2"},
+		{"/e^f", "3"},
+		{"/g|h", "4"},
+		{"/i\\j", "5"},
+		{"/k\"l", "6"},
+		{"/ ", "7"},
+		{"/m~0n", "8"},
+		{"/o//", "9"},
+		{"/foo/01", ""},
+		{"/foo/-", ""},
+		{"/foo/x", ""},
+		{"/foo/2", ""},
+	}
+	for _, c := range cases {
+		t.Run(c.pointer, func(t *testing.T) {
+			actual, err := GetStrict(json, c.pointer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual.Raw != c.expected {
+				t.Fatalf("expected %q, got %q", c.expected, actual.Raw)
+			}
+		})
+	}
+
+	whole, err := GetStrict(json, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, whole.IsObject())
+
+	for _, pointer := range []string{"foo", "/m~n", "/m~", "/m~/n"} {
+		_, err := GetStrict(json, pointer)
+		if _, ok := err.(*PointerSyntaxError); !ok {
+			t.Fatalf("expected a syntax error for %q, got %v", pointer, err)
+		}
+	}
+}
+
+func TestParsePointerStrict(t *testing.T) {
+	p, err := ParsePointerStrict("/a~1b//c~0d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, p.Strict())
+	assert(t, p.Len() == 3)
+	assert(t, p.Token(0) == "a/b")
+	assert(t, p.Token(1) == "")
+	assert(t, p.Token(2) == "c~d")
+	assert(t, p.String() == "/a~1b//c~0d")
+	assert(t, p.Get(`{"a/b":{"":{"c~d":true}}}`).Bool())
+	assert(t, !p.Get(`{"a/b":{"c~d":true}}`).Exists())
+}
+
+func TestArrayIndex(t *testing.T) {
+	assert(t, arrayIndex("0") == 0)
+	assert(t, arrayIndex("007") == 7)
+	assert(t, arrayIndex(strconv.Itoa(math.MaxInt)) == math.MaxInt)
+	assert(t, arrayIndex("") == -1)
+	assert(t, arrayIndex("-1") == -1)
+	assert(t, arrayIndex("9223372036854775808") == -1)
+	assert(t, arrayIndex("18446744073709551617") == -1)
+
+	// an index that overflows does not wrap around to a small index; it is
+	// treated like any other token that is not an index
+	json := `["a","b","c"]`
+	assert(t, Get(json, "/18446744073709551617").Raw == json)
+	p, err := ParsePointer("/18446744073709551617")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, p.Get(json).Raw == json)
+	_, err = p.Lookup(json)
+	perr, ok := err.(*PointerError)
+	assert(t, ok && perr.Kind == InvalidIndex)
+}
+
+func TestStrictArrayIndex(t *testing.T) {
+	assert(t, strictArrayIndex("0") == 0)
+	assert(t, strictArrayIndex("42") == 42)
+	assert(t, strictArrayIndex(strconv.Itoa(math.MaxInt)) == math.MaxInt)
+	assert(t, strictArrayIndex("") == -1)
+	assert(t, strictArrayIndex("01") == -1)
+	assert(t, strictArrayIndex("-1") == -1)
+	assert(t, strictArrayIndex("1a") == -1)
+	assert(t, strictArrayIndex("21000000000000000000") == -1)
+	assert(t, strictArrayIndex("9223372036854775808") == -1)
+	assert(t, strictArrayIndex("99999999999999999999999") == -1)
+}
+
+func TestLookup(t *testing.T) {
+	json := `{"name":{"first":"Janet","last":"Prichard"},"age":47,"tags":["a","b"],"nested":[{"x":1}]}`
+
+	value, err := Lookup(json, "/name/last")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, value.String() == "Prichard")
+	assert(t, value.Index == 32)
+
+	cases := []struct {
+		json       string
+		pointer    string
+		kind       PointerErrorKind
+		token      string
+		tokenIndex int
+		offset     int
+		typ        Type
+	}{
+		{json, "/name/middle", KeyNotFound, "middle", 1, 42, Null},
+		{json, "/missing", KeyNotFound, "missing", 0, 88, Null},
+		{json, "/name/first/x", NotContainer, "x", 2, 17, String},
+		{json, "/age/0", NotContainer, "0", 1, 50, Number},
+		{json, "/tags/2", IndexOutOfRange, "2", 1, 68, Null},
+		{json, "/tags/x", InvalidIndex, "x", 1, 60, Null},
+		{json, "/nested/0/y", KeyNotFound, "y", 2, 86, Null},
+		{`{"a":[1,2`, "/a/5", MalformedJSON, "5", 1, 9, Null},
+		{`{"a":"b`, "/a", MalformedJSON, "a", 0, 7, Null},
+		{`"hello"`, "/a", NotContainer, "a", 0, 0, String},
+		{``, "", MalformedJSON, "", 0, 0, Null},
+	}
+	for _, c := range cases {
+		t.Run(c.pointer, func(t *testing.T) {
+			_, err := Lookup(c.json, c.pointer)
+			perr, ok := err.(*PointerError)
+			if !ok {
+				t.Fatalf("expected a *PointerError, got %v", err)
+			}
+			expected := PointerError{
+				Kind:       c.kind,
+				Pointer:    c.pointer,
+				Token:      c.token,
+				TokenIndex: c.tokenIndex,
+				Offset:     c.offset,
+				Type:       c.typ,
+			}
+			if *perr != expected {
+				t.Fatalf("expected %#v, got %#v", expected, *perr)
+			}
+		})
+	}
+}
+
+func TestPointerLookup(t *testing.T) {
+	json := []byte(`{"foo":["bar","baz"]}`)
+
+	p, err := ParsePointerStrict("/foo/-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = p.LookupBytes(json)
+	perr, ok := err.(*PointerError)
+	assert(t, ok)
+	assert(t, perr.Kind == IndexOutOfRange)
+	assert(t, perr.Pointer == "/foo/-")
+
+	p = MustParsePointer("/foo/1")
+	value, err := p.LookupBytes(json)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, value.String() == "baz")
+}
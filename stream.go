@@ -0,0 +1,397 @@
+package jp
+
+import (
+	"io"
+)
+
+// streamBufferSize is the initial size of a StreamDecoder's buffer.
+const streamBufferSize = 4096
+
+// StreamDecoder reads a JSON document from an io.Reader and iterates over the
+// elements of an array within it without holding the entire document in
+// memory. The decoder's buffer only needs to be large enough to hold the
+// largest element of the array: values that precede the array are skipped as
+// they are read, and each element is discarded once the iterator moves past
+// it.
+//
+// A StreamDecoder reads a single document, and Range should be called at most
+// once.
+type StreamDecoder struct {
+	r   io.Reader
+	err error
+	eof bool
+
+	// buf holds the unconsumed input. offset is the offset of buf[0] within
+	// the document, and pos is the offset of the next byte to consume within
+	// buf.
+	buf    []byte
+	offset int
+	pos    int
+}
+
+// NewStreamDecoder returns a StreamDecoder that reads from r.
+func NewStreamDecoder(r io.Reader) *StreamDecoder {
+	return &StreamDecoder{r: r}
+}
+
+// StreamIterator iterates over the elements of an array read by a
+// StreamDecoder.
+type StreamIterator struct {
+	d       *StreamDecoder
+	pointer string
+	tokens  []referenceToken
+
+	started bool
+	done    bool
+	err     error
+	value   Result[[]byte]
+}
+
+// Range returns an iterator over the elements of the array referred to by the
+// pointer, which is interpreted as it would be by ParsePointer.
+//
+// If the pointer does not refer to an array, the iterator yields no elements
+// and its Err method returns a *PointerError.
+func (d *StreamDecoder) Range(pointer string) *StreamIterator {
+	it := &StreamIterator{d: d, pointer: pointer}
+	p, err := ParsePointer(pointer)
+	if err != nil {
+		it.done, it.err = true, err
+		return it
+	}
+	it.tokens = p.tokens
+	return it
+}
+
+// Next advances the iterator to the next element of the array. It returns
+// false at the end of the array or if an error occurs. In the latter case,
+// Err returns the error.
+func (it *StreamIterator) Next() bool {
+	if it.done {
+		return false
+	}
+	if !it.started {
+		it.started = true
+		if err := it.d.navigate(it.tokens); err != nil {
+			err.Pointer = it.pointer
+			return it.fail(err)
+		}
+	}
+
+	value, end, err := it.d.next()
+	switch {
+	case err != nil:
+		return it.fail(err)
+	case end:
+		it.done = true
+		return false
+	default:
+		it.value = value
+		return true
+	}
+}
+
+// Value returns the current element. The element's Raw field refers to the
+// decoder's buffer, and is only valid until the next call to Next. The
+// element's Index is its offset within the document.
+func (it *StreamIterator) Value() Result[[]byte] {
+	return it.value
+}
+
+// Err returns the error, if any, that stopped the iteration. Errors returned
+// by the underlying reader are returned as-is. Pointers that do not refer to
+// an array are reported as *PointerError, and malformed or truncated arrays
+// are reported as *SyntaxError.
+func (it *StreamIterator) Err() error {
+	return it.err
+}
+
+func (it *StreamIterator) fail(err error) bool {
+	it.done, it.err, it.value = true, err, Result[[]byte]{}
+	return false
+}
+
+// fill discards the consumed input and reads more data into the buffer. The
+// buffer is grown if it is full. It returns false if no more data is
+// available.
+func (d *StreamDecoder) fill() bool {
+	if d.eof {
+		return false
+	}
+	if d.pos > 0 {
+		n := copy(d.buf, d.buf[d.pos:])
+		d.buf, d.offset, d.pos = d.buf[:n], d.offset+d.pos, 0
+	}
+	if len(d.buf) == cap(d.buf) {
+		size := 2 * cap(d.buf)
+		if size < streamBufferSize {
+			size = streamBufferSize
+		}
+		buf := make([]byte, len(d.buf), size)
+		copy(buf, d.buf)
+		d.buf = buf
+	}
+
+	for {
+		n, err := d.r.Read(d.buf[len(d.buf):cap(d.buf)])
+		d.buf = d.buf[:len(d.buf)+n]
+		if err != nil {
+			if err != io.EOF {
+				d.err = err
+			}
+			d.eof = true
+			return n > 0
+		}
+		if n > 0 {
+			return true
+		}
+	}
+}
+
+// peek returns the next unconsumed byte, reading more input if necessary.
+func (d *StreamDecoder) peek() (byte, bool) {
+	for d.pos == len(d.buf) {
+		if !d.fill() {
+			return 0, false
+		}
+	}
+	return d.buf[d.pos], true
+}
+
+// skipSpace consumes whitespace and, if commas is true, commas. It returns the
+// next byte.
+func (d *StreamDecoder) skipSpace(commas bool) (byte, bool) {
+	for {
+		c, ok := d.peek()
+		if !ok || c > ' ' && (c != ',' || !commas) {
+			return c, ok
+		}
+		d.pos++
+	}
+}
+
+// truncated returns the error that describes the end of the input.
+func (d *StreamDecoder) truncated() error {
+	if d.err != nil {
+		return d.err
+	}
+	return &SyntaxError{Offset: d.offset + len(d.buf), msg: "unexpected end of JSON input"}
+}
+
+// valueType returns the type of the value that begins with c.
+func valueType(c byte) Type {
+	switch c {
+	case '"':
+		return String
+	case '{', '[':
+		return JSON
+	case 't':
+		return True
+	case 'f':
+		return False
+	case 'n':
+		return Null
+	default:
+		return Number
+	}
+}
+
+// navigate consumes the input up to the opening bracket of the array
+// referred to by tokens.
+func (d *StreamDecoder) navigate(tokens []referenceToken) *PointerError {
+	fail := func(kind PointerErrorKind, depth int, typ Type) *PointerError {
+		err := &PointerError{Kind: kind, TokenIndex: depth, Offset: d.offset + d.pos, Type: typ}
+		if depth < len(tokens) {
+			err.Token = tokens[depth].key
+		}
+		return err
+	}
+
+	for depth := 0; ; depth++ {
+		c, ok := d.skipSpace(false)
+		if !ok {
+			return fail(MalformedJSON, depth, Null)
+		}
+		if depth == len(tokens) {
+			if c != '[' {
+				// every token resolved, so the error refers to the last one
+				err := &PointerError{Kind: NotContainer, Offset: d.offset + d.pos, Type: valueType(c), msg: "value is not an array"}
+				if depth > 0 {
+					err.Token, err.TokenIndex = tokens[depth-1].key, depth-1
+				}
+				return err
+			}
+			d.pos++
+			return nil
+		}
+
+		token := tokens[depth]
+		switch c {
+		case '{':
+			d.pos++
+			for {
+				c, ok := d.skipSpace(true)
+				switch {
+				case !ok:
+					return fail(MalformedJSON, depth, Null)
+				case c == '}':
+					return fail(KeyNotFound, depth, Null)
+				case c != '"':
+					return fail(MalformedJSON, depth, Null)
+				}
+
+				key, ok := d.key()
+				if !ok {
+					return fail(MalformedJSON, depth, Null)
+				}
+				if c, ok = d.skipSpace(false); !ok || c != ':' {
+					return fail(MalformedJSON, depth, Null)
+				}
+				d.pos++
+				if key == token.key {
+					break
+				}
+				if c, ok = d.skipSpace(false); !ok || !d.skipValue() {
+					return fail(MalformedJSON, depth, Null)
+				}
+			}
+		case '[':
+			if token.index == -1 {
+				return fail(InvalidIndex, depth, Null)
+			}
+			d.pos++
+			for i := 0; ; i++ {
+				c, ok := d.skipSpace(true)
+				switch {
+				case !ok:
+					return fail(MalformedJSON, depth, Null)
+				case c == ']':
+					return fail(IndexOutOfRange, depth, Null)
+				}
+				if i == token.index {
+					break
+				}
+				if !d.skipValue() {
+					return fail(MalformedJSON, depth, Null)
+				}
+			}
+		default:
+			return fail(NotContainer, depth, valueType(c))
+		}
+	}
+}
+
+// key consumes the string that begins at the current position and returns
+// its unescaped contents.
+func (d *StreamDecoder) key() (string, bool) {
+	for {
+		i, raw, esc, ok := parseString(d.buf, d.pos+1)
+		if ok {
+			d.pos = i
+			if esc {
+				return unescape(raw[1 : len(raw)-1]), true
+			}
+			return string(raw[1 : len(raw)-1]), true
+		}
+		if !d.fill() {
+			return "", false
+		}
+	}
+}
+
+// skipValue consumes the value that begins at the current position without
+// retaining it.
+func (d *StreamDecoder) skipValue() bool {
+	c, _ := d.peek()
+	switch c {
+	case '"':
+		return d.skipString()
+	case '{', '[':
+		depth := 0
+		for {
+			c, ok := d.peek()
+			if !ok {
+				return false
+			}
+			switch c {
+			case '"':
+				if !d.skipString() {
+					return false
+				}
+				continue
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+			}
+			d.pos++
+			if depth == 0 {
+				return true
+			}
+		}
+	default:
+		for {
+			c, ok := d.peek()
+			if !ok {
+				return true
+			}
+			if c <= ' ' || c == ',' || c == ']' || c == '}' {
+				return true
+			}
+			d.pos++
+		}
+	}
+}
+
+// skipString consumes the string that begins at the current position.
+func (d *StreamDecoder) skipString() bool {
+	d.pos++
+	for {
+		c, ok := d.peek()
+		if !ok {
+			return false
+		}
+		d.pos++
+		switch c {
+		case '"':
+			return true
+		case '\\':
+			if _, ok := d.peek(); !ok {
+				return false
+			}
+			d.pos++
+		}
+	}
+}
+
+// next consumes the next element of the current array. It returns true if
+// the end of the array has been reached.
+func (d *StreamDecoder) next() (Result[[]byte], bool, error) {
+	c, ok := d.skipSpace(true)
+	if !ok {
+		return Result[[]byte]{}, false, d.truncated()
+	}
+	if c == ']' {
+		d.pos++
+		return Result[[]byte]{}, true, nil
+	}
+
+	// An element is only complete once the byte that follows it has been
+	// read: until then, a number or literal may continue, and an object or
+	// array may be unterminated.
+	for {
+		i, value, ok := parseAny(d.buf, d.pos, true)
+		if ok && i < len(d.buf) {
+			value.Index = d.offset + d.pos
+			d.pos = i
+			return value, false, nil
+		}
+		if !d.fill() {
+			return Result[[]byte]{}, false, d.truncated()
+		}
+		// fill the buffer before rescanning the element so that large
+		// elements are not rescanned after every read
+		for len(d.buf) < cap(d.buf) && d.fill() {
+		}
+	}
+}
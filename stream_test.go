@@ -0,0 +1,155 @@
+package jp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func collectStream(t *testing.T, r io.Reader, pointer string) ([]Result[[]byte], []string, error) {
+	var results []Result[[]byte]
+	var raws []string
+	it := NewStreamDecoder(r).Range(pointer)
+	for it.Next() {
+		results = append(results, it.Value())
+		raws = append(raws, string(it.Value().Raw))
+	}
+	return results, raws, it.Err()
+}
+
+func TestStreamDecoder(t *testing.T) {
+	json := `{"meta":{"skip":["]",{"x":"\"}"}],"n":-1.5e3},"items":[1, "two" ,{"three":[3]},[4],true,null , -5.5 ],"after":1}`
+	expected := Get(json, "/items").Array()
+
+	readers := map[string]func() io.Reader{
+		"reader":   func() io.Reader { return strings.NewReader(json) },
+		"onebyte":  func() io.Reader { return iotest.OneByteReader(strings.NewReader(json)) },
+		"halfread": func() io.Reader { return iotest.HalfReader(strings.NewReader(json)) },
+		"dataerr":  func() io.Reader { return iotest.DataErrReader(strings.NewReader(json)) },
+	}
+	for name, reader := range readers {
+		t.Run(name, func(t *testing.T) {
+			results, raws, err := collectStream(t, reader(), "/items")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(raws) != len(expected) {
+				t.Fatalf("expected %v elements, got %v", len(expected), len(raws))
+			}
+			for i, e := range expected {
+				if raws[i] != e.Raw || results[i].Type != e.Type || results[i].Str != e.Str || results[i].Num != e.Num {
+					t.Fatalf("element %v: expected %#v, got %#v", i, e, results[i])
+				}
+				assert(t, results[i].Index == Get(json, fmt.Sprintf("/items/%d", i)).Index)
+			}
+		})
+	}
+
+	_, raws, err := collectStream(t, strings.NewReader(`[[1],[2,[3]]]`), "/1/1")
+	assert(t, err == nil)
+	assert(t, strings.Join(raws, ",") == "3")
+
+	_, raws, err = collectStream(t, strings.NewReader(` [ ] `), "")
+	assert(t, err == nil && len(raws) == 0)
+}
+
+func TestStreamDecoderLarge(t *testing.T) {
+	var b strings.Builder
+	b.WriteString(`{"skip":"`)
+	b.WriteString(strings.Repeat("x", 100000))
+	b.WriteString(`","items":[`)
+	for i := 0; i < 10000; i++ {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `{"id":%d,"name":"item %d"}`, i, i)
+	}
+	b.WriteString(`]}`)
+
+	d := NewStreamDecoder(strings.NewReader(b.String()))
+	it := d.Range("/items")
+	n := 0
+	for it.Next() {
+		assert(t, it.Value().Get("/id").Int() == int64(n))
+		n++
+	}
+	if it.Err() != nil {
+		t.Fatal(it.Err())
+	}
+	assert(t, n == 10000)
+	// the buffer only needs to hold a single element, not the skipped string
+	assert(t, cap(d.buf) < 100000)
+}
+
+func TestStreamDecoderErrors(t *testing.T) {
+	cases := []struct {
+		json    string
+		pointer string
+		count   int
+		kind    PointerErrorKind
+	}{
+		{`{"a":[1,2]}`, "/b", 0, KeyNotFound},
+		{`{"a":[1,2]}`, "/a/5", 0, IndexOutOfRange},
+		{`{"a":[1,2]}`, "/a/x", 0, InvalidIndex},
+		{`{"a":"b"}`, "/a", 0, NotContainer},
+		{`{"a":{}}`, "/a", 0, NotContainer},
+		{`{"a":"b"}`, "/a/b", 0, NotContainer},
+		{`{"a":[1,2]`, "/b", 0, MalformedJSON},
+	}
+	for _, c := range cases {
+		t.Run(c.json+c.pointer, func(t *testing.T) {
+			_, raws, err := collectStream(t, strings.NewReader(c.json), c.pointer)
+			assert(t, len(raws) == c.count)
+			perr, ok := err.(*PointerError)
+			if !ok {
+				t.Fatalf("expected a *PointerError, got %v", err)
+			}
+			assert(t, perr.Kind == c.kind)
+			assert(t, perr.Pointer == c.pointer)
+		})
+	}
+
+	// a pointer that resolves to a value that is not an array reports the
+	// last reference token
+	_, _, err := collectStream(t, strings.NewReader(`{"a":{"b":1}}`), "/a")
+	perr, ok := err.(*PointerError)
+	if !ok {
+		t.Fatalf("expected a *PointerError, got %v", err)
+	}
+	assert(t, perr.Kind == NotContainer && perr.Token == "a" && perr.TokenIndex == 0 && perr.Type == JSON)
+	assert(t, perr.Error() == `resolving JSON pointer "/a": value is not an array at offset 5`)
+
+	truncated := []struct {
+		json  string
+		count int
+	}{
+		{`[1,2,`, 2},
+		{`[1,2`, 1},
+		{`[1,{"a":2}`, 1},
+		{`[1,"abc`, 1},
+		{`[1,{"a":[2]`, 1},
+	}
+	for _, c := range truncated {
+		t.Run(c.json, func(t *testing.T) {
+			_, raws, err := collectStream(t, strings.NewReader(c.json), "")
+			assert(t, len(raws) == c.count)
+			serr, ok := err.(*SyntaxError)
+			if !ok {
+				t.Fatalf("expected a *SyntaxError, got %v", err)
+			}
+			assert(t, serr.Offset == len(c.json))
+		})
+	}
+
+	readErr := errors.New("boom")
+	_, raws, err := collectStream(t, io.MultiReader(strings.NewReader(`[1,2,`), iotest.ErrReader(readErr)), "")
+	assert(t, len(raws) == 2)
+	assert(t, err == readErr)
+
+	_, _, err = collectStream(t, strings.NewReader(`[]`), "/a~2")
+	_, ok = err.(*PointerSyntaxError)
+	assert(t, ok)
+}